@@ -5,6 +5,7 @@ import (
 	"github.com/pkg/errors"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -22,6 +23,11 @@ type ActiveTick struct {
 	tries         uint8
 	baseurl       string
 	symbol_prefix string
+	cassette      *Cassette
+	replay        bool
+	instruments   *InstrumentRegistry
+	decimals      bool
+	decimalScale  uint8
 }
 
 func NewActiveTick(port uint16, host string, tries uint8, symbol_prefix string) ActiveTick {
@@ -39,6 +45,47 @@ func NewActiveTick(port uint16, host string, tries uint8, symbol_prefix string)
 	return at
 }
 
+// Instruments registry is used To map our symbols To ActiveTick ones. If instrument has ActiveTick alias it's used
+// as is, options (OCC symbols) are converted To ActiveTick option symbols, otherwise symbol prefix is added To symbol.
+func (a *ActiveTick) SetInstruments(instruments *InstrumentRegistry) {
+	a.instruments = instruments
+}
+
+// Enables exact decimal prices in parsed ticks and candles. Scale is taken From instrument tick size if it's known,
+// otherwise default scale is used. ActiveTick sends 6 digits after point.
+func (a *ActiveTick) SetDecimalPrices(defaultScale uint8) {
+	a.decimals = true
+	a.decimalScale = defaultScale
+}
+
+// Returns scale of decimal prices or noDecimals if they are disabled
+func (a ActiveTick) priceScale(symbol string) int {
+	if !a.decimals {
+		return noDecimals
+	}
+	if i, err := a.instruments.Get(symbol); err == nil && i.TickSize > 0 {
+		return int(scaleOfTickSize(i.TickSize))
+	}
+	return int(a.decimalScale)
+}
+
+func (a ActiveTick) vendorSymbol(symbol string) string {
+	if i, err := a.instruments.Get(symbol); err == nil {
+		if alias, ok := i.Aliases[ActiveTickVendor]; ok && alias != "" {
+			return alias
+		}
+	}
+	if contract, err := ParseOCCSymbol(symbol); err == nil {
+		return activeTickOptionSymbol(contract)
+	}
+	return a.symbol_prefix + symbol
+}
+
+// ActiveTick option symbol is padded OCC symbol with dot prefix: ".AAPL  210917C00150000"
+func activeTickOptionSymbol(contract *OptionContract) string {
+	return "." + contract.OCCSymbol()
+}
+
 func (a ActiveTick) GetCandles(symbol string, timeFrame string, dRange DateRange) (CandleArray, error) {
 
 	from := convertTimeToActiveTickFormat(dRange.From)
@@ -50,10 +97,10 @@ func (a ActiveTick) GetCandles(symbol string, timeFrame string, dRange DateRange
 
 	case "D":
 		uri = fmt.Sprintf("/barData?symbol=%v&historyType=1&beginTime=%v&endTime=%v",
-			a.symbol_prefix+strings.ToUpper(symbol), from, to)
+			url.QueryEscape(a.vendorSymbol(strings.ToUpper(symbol))), from, to)
 	case "W":
 		uri = fmt.Sprintf("/barData?symbol=%v&historyType=2&beginTime=%v&endTime=%v",
-			a.symbol_prefix+strings.ToUpper(symbol), from, to)
+			url.QueryEscape(a.vendorSymbol(strings.ToUpper(symbol))), from, to)
 
 	default:
 		mins, err := strconv.Atoi(timeFrame)
@@ -64,7 +111,7 @@ func (a ActiveTick) GetCandles(symbol string, timeFrame string, dRange DateRange
 			return nil, errors.New("Intraday minutes should be From 1 To 60")
 		}
 		uri = fmt.Sprintf("/barData?symbol=%v&historyType=0&intradayMinutes=%v&beginTime=%v&endTime=%v",
-			a.symbol_prefix+strings.ToUpper(symbol), timeFrame, from, to)
+			url.QueryEscape(a.vendorSymbol(strings.ToUpper(symbol))), timeFrame, from, to)
 
 	}
 
@@ -74,7 +121,7 @@ func (a ActiveTick) GetCandles(symbol string, timeFrame string, dRange DateRange
 		return nil, err
 	}
 
-	candles, err := parseToCandlesList(rawData, symbol)
+	candles, err := parseToCandlesList(rawData, symbol, a.priceScale(strings.ToUpper(symbol)))
 
 	return candles, err
 }
@@ -104,7 +151,7 @@ func (a ActiveTick) GetTicks(symbol string, dRange DateRange, quotes bool, trade
 	to := convertTimeToActiveTickFormat(dRange.To)
 
 	uri := fmt.Sprintf("/tickData?symbol=%v&trades=%v&quotes=%v&beginTime=%v&endTime=%v",
-		a.symbol_prefix+symbol, t, q, from, to)
+		url.QueryEscape(a.vendorSymbol(symbol)), t, q, from, to)
 
 	rawData, err := a.getRawData(uri)
 
@@ -112,7 +159,7 @@ func (a ActiveTick) GetTicks(symbol string, dRange DateRange, quotes bool, trade
 		return nil, err
 	}
 
-	ticks, err := parseToTQ(rawData)
+	ticks, err := parseToTQ(rawData, a.priceScale(symbol))
 	if err != nil {
 		return nil, err
 	}
@@ -121,20 +168,43 @@ func (a ActiveTick) GetTicks(symbol string, dRange DateRange, quotes bool, trade
 
 }
 
+// Option candles. ActiveTick fills open interest for option bars
+func (a ActiveTick) GetOptionCandles(contract OptionContract, timeFrame string, dRange DateRange) (CandleArray, error) {
+	return a.GetCandles(contract.Symbol(), timeFrame, dRange)
+}
+
+func (a ActiveTick) GetOptionTicks(contract OptionContract, dRange DateRange, quotes bool, trades bool) (TickArray, error) {
+	ticks, err := a.GetTicks(contract.Symbol(), dRange, quotes, trades)
+	for _, t := range ticks {
+		t.Symbol = contract.Symbol()
+	}
+	return ticks, err
+}
+
+// Returns all option contracts of underlying sorted by expiry, strike and right
+func (a ActiveTick) GetOptionChain(underlying string) ([]*OptionContract, error) {
+	uri := fmt.Sprintf("/optionChain?symbol=%v", url.QueryEscape(a.vendorSymbol(strings.ToUpper(underlying))))
+
+	rawData, err := a.getRawData(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseOptionChain(rawData)
+}
+
 func (a ActiveTick) GetQuotesSnapshot(symbols []string) ([]QuoteSnapshot, error) {
 	return nil, nil
 
 }
 
 func (a ActiveTick) getRawData(uri string) (string, error) {
-	url := a.baseurl + uri
-
 	var tries uint8
 
 	for {
 		tries ++
 
-		content, err := getResponse(url)
+		content, err := a.request(uri)
 
 		if err != nil {
 			switch errors.Cause(err).(type) {
@@ -146,6 +216,8 @@ func (a ActiveTick) getRawData(uri string) (string, error) {
 				return "", errors.Wrapf(err, "getRawData(%v)", uri)
 			case *ErrDatasourceNotConnected:
 				return "", errors.Wrapf(err, "getRawData(%v)", uri)
+			case *ErrCassetteEntryNotFound:
+				return "", errors.Wrapf(err, "getRawData(%v)", uri)
 
 			default:
 				if tries > a.tries {
@@ -160,28 +232,69 @@ func (a ActiveTick) getRawData(uri string) (string, error) {
 
 }
 
-func getResponse(url string) (string, error) {
-	response, err := http.Get(url)
+// Makes request To datasource or cassette depending on mode
+func (a ActiveTick) request(uri string) (string, error) {
+	url := a.baseurl + uri
 
-	if err != nil {
-		if strings.Contains(err.Error(), "target machine actively refused") {
-			return "", &ErrDatasourceNotConnected{"ActiveTick"}
+	if a.cassette == nil {
+		return getResponse(url)
+	}
+
+	if a.replay {
+		entry, err := a.cassette.Load(uri)
+		if err != nil {
+			return "", err
 		}
+		return checkResponse(entry.Code, entry.Body, url)
+	}
+
+	code, content, err := fetchResponse(url)
+	if err != nil {
+		return "", err
+	}
+
+	err = a.cassette.Save(CassetteEntry{uri, code, content})
+	if err != nil {
+		return "", err
+	}
+
+	return checkResponse(code, content, url)
+}
+
+func getResponse(url string) (string, error) {
+	code, content, err := fetchResponse(url)
+	if err != nil {
 		return "", err
 	}
 
-	if response.StatusCode != 200 {
-		return "", &ErrUnexpectedResponseCode{uint16(response.StatusCode), url}
+	return checkResponse(code, content, url)
+}
+
+func fetchResponse(url string) (int, string, error) {
+	response, err := http.Get(url)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "target machine actively refused") ||
+			strings.Contains(err.Error(), "connection refused") {
+			return 0, "", &ErrDatasourceNotConnected{"ActiveTick"}
+		}
+		return 0, "", err
 	}
 
 	defer response.Body.Close()
 
 	content, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		return "", err
+		return 0, "", err
 	}
 
-	content_s := string(content)
+	return response.StatusCode, string(content), nil
+}
+
+func checkResponse(code int, content_s string, url string) (string, error) {
+	if code != 200 {
+		return "", &ErrUnexpectedResponseCode{uint16(code), url}
+	}
 
 	if strings.HasPrefix(content_s, "0") {
 		fmt.Println("Prefix: " + content_s)
@@ -190,11 +303,19 @@ func getResponse(url string) (string, error) {
 		}
 		return "", &ErrEmptyResponse{url}
 	}
-	return string(content_s), err
+	return content_s, nil
+
+}
 
+// Parses decimal copy of price field if scale isn't noDecimals
+func parseDecimalField(s string, scale int) (Decimal, error) {
+	if scale == noDecimals {
+		return Decimal{}, nil
+	}
+	return ParseDecimal(s, uint8(scale))
 }
 
-func parseToCandlesList(raw string, symbol string) (CandleArray, error) {
+func parseToCandlesList(raw string, symbol string, scale int) (CandleArray, error) {
 	if raw == "" {
 		return nil, &ErrNothingToParse{}
 	}
@@ -205,7 +326,8 @@ func parseToCandlesList(raw string, symbol string) (CandleArray, error) {
 
 		s := strings.Split(l, ",")
 
-		if len(s) != 6 {
+		// Option bars have open interest in additional column
+		if len(s) != 6 && len(s) != 7 {
 			if !strings.Contains(l, ",") {
 				continue
 			}
@@ -246,6 +368,14 @@ func parseToCandlesList(raw string, symbol string) (CandleArray, error) {
 			return nil, &ErrParsingMarketData{l, "Candle"}
 		}
 
+		var openInterest int64
+		if len(s) == 7 {
+			openInterest, err = strconv.ParseInt(s[6], 10, 64)
+			if err != nil {
+				return nil, &ErrParsingMarketData{l, "Candle"}
+			}
+		}
+
 		candle := Candle{
 			Symbol: symbol,
 			Open:         open,
@@ -254,10 +384,22 @@ func parseToCandlesList(raw string, symbol string) (CandleArray, error) {
 			Close:        close_,
 			AdjClose:     close_,
 			Volume:       volume,
-			OpenInterest: 0,
+			OpenInterest: openInterest,
 			Datetime:     datetime,
 		}
 
+		if scale != noDecimals {
+			var exact CandleDecimals
+			for i, d := range []*Decimal{&exact.Open, &exact.High, &exact.Low, &exact.Close} {
+				*d, err = parseDecimalField(s[i+1], scale)
+				if err != nil {
+					return nil, &ErrParsingMarketData{l, "Candle"}
+				}
+			}
+			exact.AdjClose = exact.Close
+			candle.Exact = &exact
+		}
+
 		candles = append(candles, &candle)
 
 	}
@@ -265,7 +407,32 @@ func parseToCandlesList(raw string, symbol string) (CandleArray, error) {
 	return candles, nil
 }
 
-func parseToTQ(raw string) (TickArray, error) {
+func parseOptionChain(raw string) ([]*OptionContract, error) {
+	if raw == "" {
+		return nil, &ErrNothingToParse{}
+	}
+	lines := strings.Split(raw, "\r\n")
+	var contracts []*OptionContract
+
+	for _, l := range lines {
+		symbol := strings.TrimSpace(strings.Split(l, ",")[0])
+		if symbol == "" {
+			continue
+		}
+
+		contract, err := ParseOCCSymbol(strings.TrimPrefix(symbol, "."))
+		if err != nil {
+			return nil, &ErrParsingMarketData{l, "OptionContract"}
+		}
+		contracts = append(contracts, contract)
+	}
+
+	sortOptionContracts(contracts)
+
+	return contracts, nil
+}
+
+func parseToTQ(raw string, scale int) (TickArray, error) {
 	if raw == "" {
 		return nil, &ErrNothingToParse{}
 	}
@@ -284,20 +451,22 @@ func parseToTQ(raw string) (TickArray, error) {
 		}
 
 		if s[0] == "T" {
-			tq, err := parseTickLine(s)
+			tq, err := parseTickLine(s, scale)
 			if err != nil {
 				return nil, err
 			}
+			tq.Seq = int64(len(ticks))
 			ticks = append(ticks, tq)
 			continue
 		}
 
 		if s[0] == "Q" {
-			tq, err := parseQuoteLine(s)
+			tq, err := parseQuoteLine(s, scale)
 			if err != nil {
 				return nil, err
 			}
 
+			tq.Seq = int64(len(ticks))
 			ticks = append(ticks, tq)
 			continue
 		}
@@ -308,7 +477,7 @@ func parseToTQ(raw string) (TickArray, error) {
 
 }
 
-func parseTickLine(s []string) (*Tick, error) {
+func parseTickLine(s []string, scale int) (*Tick, error) {
 	// T,20120803153000551,616.550000,100,Y,0,14,0,0
 	datetime, err := getTickTime(s[1])
 
@@ -348,12 +517,22 @@ func parseTickLine(s []string) (*Tick, error) {
 		Cond3:     cond3,
 		Cond4:     cond4,
 	}
+	tick.setOpeningClosing()
+
+	if scale != noDecimals {
+		exactLast, err := parseDecimalField(s[2], scale)
+		if err != nil {
+			return nil, &ErrParsingMarketData{s[2], "Tick"}
+		}
+		missing := DecimalFromFloat(-1, uint8(scale))
+		tick.Exact = &TickDecimals{exactLast, missing, missing}
+	}
 
 	return &tick, nil
 
 }
 
-func parseQuoteLine(s []string) (*Tick, error) {
+func parseQuoteLine(s []string, scale int) (*Tick, error) {
 	// Q,20120803153000133,616.540000,616.630000,2,1,B,Q,0
 	datetime, err := getTickTime(s[1])
 
@@ -404,12 +583,27 @@ func parseQuoteLine(s []string) (*Tick, error) {
 		Cond4:     "",
 	}
 
+	if scale != noDecimals {
+		exactBid, err := parseDecimalField(s[2], scale)
+		if err != nil {
+			return nil, &ErrParsingMarketData{s[2], "Tick"}
+		}
+		exactAsk, err := parseDecimalField(s[3], scale)
+		if err != nil {
+			return nil, &ErrParsingMarketData{s[3], "Tick"}
+		}
+		tick.Exact = &TickDecimals{DecimalFromFloat(-1, uint8(scale)), exactBid, exactAsk}
+	}
+
 	return &tick, nil
 
 }
 
 func getTickTime(s string) (*time.Time, error) {
 	str_time := s
+	if len(str_time) != len(layout)+3 {
+		return nil, &ErrParsingMarketData{"Can't parse time: " + s, "Tick"}
+	}
 	datetime, err := time.Parse(layout, str_time[:len(str_time)-3])
 	if err != nil {
 		return nil, &ErrParsingMarketData{"Can't parse time: " + s, "Tick"}
@@ -423,7 +617,7 @@ func getTickTime(s string) (*time.Time, error) {
 		return nil, &ErrParsingMarketData{"Can't extract ms: " + s, "Tick"}
 	}
 
-	ms_to_add := time.Duration(ms) * time.Microsecond
+	ms_to_add := time.Duration(ms) * time.Millisecond
 	datetime = datetime.Add(ms_to_add)
 
 	return &datetime, nil
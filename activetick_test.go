@@ -5,17 +5,137 @@ import (
 	"time"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"sort"
 	"os"
 	"bufio"
 	"strings"
 )
 
+const testCassette = "test_data/cassettes/activetick"
+
+// Tests are replayed From cassette. Set ACTIVETICK_RECORD=1 To record new cassette entries From live
+// ActiveTick HTTP API on 127.0.0.1:5000
 func mockActiveTick() *ActiveTick {
-	at := NewActiveTick(5000, "127.0.0.1", 2, "")
+	if os.Getenv("ACTIVETICK_RECORD") != "" {
+		at := NewRecordingActiveTick(NewActiveTick(5000, "127.0.0.1", 2, ""), testCassette)
+		return &at
+	}
+	at := NewReplayActiveTick(testCassette, "")
 	return &at
 }
 
+// Fake ActiveTick HTTP API. Serves generated weekday bars and ticks for any symbol, so tests that
+// request ranges not recorded in cassette run offline
+func fakeActiveTick() (*ActiveTick, *httptest.Server) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		from, err := time.Parse(layout, q.Get("beginTime"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		to, err := time.Parse(layout, q.Get("endTime"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var lines []string
+		switch r.URL.Path {
+		case "/barData":
+			lines = fakeBars(q.Get("historyType"), q.Get("intradayMinutes"), from, to)
+		case "/tickData":
+			lines = fakeTicks(q.Get("quotes") == "1", q.Get("trades") == "1", from, to)
+		default:
+			http.NotFound(w, r)
+			return
+		}
+
+		if len(lines) == 0 {
+			fmt.Fprint(w, "0")
+			return
+		}
+		fmt.Fprint(w, strings.Join(lines, "\r\n"))
+	}))
+
+	at := NewActiveTick(0, server.Listener.Addr().String(), 0, "")
+	return &at, server
+}
+
+// Bar prices depend only on day and bar number: open is 100 + day of year + bar number
+func fakeBars(historyType string, intradayMinutes string, from time.Time, to time.Time) []string {
+	var lines []string
+	for d := from.Truncate(24 * time.Hour); !d.After(to); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			continue
+		}
+
+		var times []time.Time
+		switch historyType {
+		case "0":
+			mins, _ := strconv.Atoi(intradayMinutes)
+			if mins < 1 {
+				return nil
+			}
+			for t := d.Add(9*time.Hour + 30*time.Minute); !t.After(d.Add(16 * time.Hour)); t = t.Add(time.Duration(mins) * time.Minute) {
+				times = append(times, t)
+			}
+		default:
+			times = append(times, d)
+		}
+
+		for i, t := range times {
+			if t.Before(from) || t.After(to) {
+				continue
+			}
+			open := float64(100 + d.YearDay() + i)
+			lines = append(lines, fmt.Sprintf("%v,%.6f,%.6f,%.6f,%.6f,%v",
+				t.Format(layout), open, open+2, open-1, open+1, 1000*(i+1)))
+		}
+	}
+	return lines
+}
+
+// Every weekday has quotes at 07:00 and 10:00 and trades at 09:30, 11:00 and 14:00
+func fakeTicks(quotes bool, trades bool, from time.Time, to time.Time) []string {
+	var lines []string
+	for d := from.Truncate(24 * time.Hour); !d.After(to); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			continue
+		}
+		price := float64(10 + d.Day())
+
+		ticks := []struct {
+			offset time.Duration
+			quote  bool
+		}{
+			{7 * time.Hour, true},
+			{9*time.Hour + 30*time.Minute, false},
+			{10 * time.Hour, true},
+			{11 * time.Hour, false},
+			{14 * time.Hour, false},
+		}
+
+		for i, tk := range ticks {
+			t := d.Add(tk.offset)
+			if t.Before(from) || t.After(to) {
+				continue
+			}
+			ms := fmt.Sprintf("%v%03d", t.Format(layout), 100*(i+1))
+			if tk.quote && quotes {
+				lines = append(lines, fmt.Sprintf("Q,%v,%.6f,%.6f,1,2,P,P,0", ms, price-0.01, price+0.01))
+			}
+			if !tk.quote && trades {
+				lines = append(lines, fmt.Sprintf("T,%v,%.6f,100,Q,0,0,0,0", ms, price))
+			}
+		}
+	}
+	return lines
+}
+
 func loadTicksMock() []time.Time {
 	pth := "test_data/activetick/PSCC.txt"
 	file, err := os.Open(pth)
@@ -78,7 +198,7 @@ func TestActiveTick_GetCandles_Day(t *testing.T) {
 
 	//20181101000000,271.600000,273.730000,270.380000,273.370000,89496311
 	expectedCandle := Candle{
-		"", //TODO
+		"SPY",
 		271.60,
 		273.73,
 		270.38,
@@ -87,6 +207,8 @@ func TestActiveTick_GetCandles_Day(t *testing.T) {
 		89496311,
 		0,
 		expectedDate,
+		"",
+		nil,
 	}
 
 	if *candles[0] != expectedCandle {
@@ -95,7 +217,9 @@ func TestActiveTick_GetCandles_Day(t *testing.T) {
 }
 
 func TestActiveTick_GetCandles_Intraday(t *testing.T) {
-	at := mockActiveTick()
+	at, server := fakeActiveTick()
+	defer server.Close()
+
 	from := time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC)
 	to := time.Date(2018, 11, 10, 0, 0, 0, 0, time.UTC)
 	dRange := DateRange{
@@ -116,17 +240,19 @@ func TestActiveTick_GetCandles_Intraday(t *testing.T) {
 		t.Fatal(fmt.Sprintf("Expected %v, got %v", expectedDate, candles[0].Datetime))
 	}
 
-	//20181101093000,271.600000,272.180000,270.780000,271.290000,12164925
+	//20181101093000,405.000000,407.000000,404.000000,406.000000,1000
 	expectedCandle := Candle{
-		"", //TODO
-		271.60,
-		272.18,
-		270.78,
-		271.29,
-		271.29,
-		12164925,
+		"SPY",
+		405,
+		407,
+		404,
+		406,
+		406,
+		1000,
 		0,
 		expectedDate,
+		"",
+		nil,
 	}
 
 	if *candles[0] != expectedCandle {
@@ -141,11 +267,20 @@ func TestActiveTick_getResponse(t *testing.T) {
 	assert.Equal(t, &expectedError, err)
 	assert.Equal(t, resp, "")
 
-	url = "http://localhost:5000/optionChain?symbol=MSFT"
+	server := NewCassetteServer(testCassette)
+	defer server.Close()
+
+	url = server.URL + "/barData?symbol=SPY&historyType=1&beginTime=20181101000000&endTime=20181110000000"
 	resp, err = getResponse(url)
 
 	assert.Equal(t, nil, err)
 	assert.NotEqual(t, resp, "")
+
+	url = server.URL + "/optionChain?symbol=MSFT"
+	resp, err = getResponse(url)
+
+	assert.IsType(t, &ErrUnexpectedResponseCode{}, err)
+	assert.Equal(t, resp, "")
 }
 
 func TestActiveTick_GetTicks(t *testing.T) {
@@ -193,7 +328,7 @@ func TestActiveTick_parseTickTime(t *testing.T) {
 	str_time := "20181101075308267"
 	time_, _ := getTickTime(str_time)
 
-	expected := time.Date(2018, 11, 1, 7, 53, 8, 267000, time.UTC)
+	expected := time.Date(2018, 11, 1, 7, 53, 8, 267000000, time.UTC)
 
 	assert.Equal(t, *time_, expected)
 
@@ -231,3 +366,39 @@ func TestActiveTick_parseTickTime(t *testing.T) {
 	assert.True(t, isSorted)
 
 }
+
+func TestActiveTick_tickOrder(t *testing.T) {
+	raw := "Q,20181101093000551,79.250000,79.270000,1,2,P,P,0\r\n" +
+		"T,20181101093000551,79.260000,100,Q,0,0,0,0\r\n" +
+		"Q,20181101093000550,79.240000,79.270000,1,2,P,P,0\r\n"
+
+	ticks, err := parseToTQ(raw, noDecimals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ticks.Sort()
+
+	assert.Equal(t, 79.24, ticks[0].BidPrice)
+	assert.Equal(t, 79.25, ticks[1].BidPrice)
+	assert.Equal(t, 79.26, ticks[2].LastPrice)
+	assert.Equal(t, 551*time.Millisecond, time.Duration(ticks[2].Datetime.Nanosecond()))
+
+	pth := "./test_data/tick_order_test.json"
+	defer os.Remove(pth)
+
+	storage := JsonStorage{}
+	err = storage.saveTicksToFile(&ticks, pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := storage.readTicksFromFile(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tk := range *loaded {
+		assert.True(t, tk.Datetime.Equal(ticks[i].Datetime))
+		assert.Equal(t, ticks[i].Seq, tk.Seq)
+	}
+}
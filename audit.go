@@ -0,0 +1,404 @@
+package marketdata
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type AuditIssueType string
+
+const (
+	IssueMissingFile        AuditIssueType = "missing_file"         // Date is listed in meta but file doesn't exist
+	IssueUnlistedFile       AuditIssueType = "unlisted_file"        // File exists but date isn't listed in meta
+	IssueEmptyTickDay       AuditIssueType = "empty_tick_day"       // Tick file without ticks
+	IssueBadCandle          AuditIssueType = "bad_candle"           // High < Low or Open/Close outside of range
+	IssueZeroVolume         AuditIssueType = "zero_volume"          // Daily candle without volume
+	IssueDuplicateTimestamp AuditIssueType = "duplicate_timestamp"  // Candles with the same time or repeated ticks
+	IssueCandleTickMismatch AuditIssueType = "candle_tick_mismatch" // Daily candle differs From aggregated trades
+	IssueCalendarGap        AuditIssueType = "calendar_gap"         // Trading day without data between stored days
+	IssueUnreadableFile     AuditIssueType = "unreadable_file"      // Data or meta file can't be read or parsed
+)
+
+type AuditIssue struct {
+	Symbol  string
+	Type    AuditIssueType
+	Dataset string // Storage folder: candles/day, ticks/trades...
+	Date    time.Time
+	Details string
+}
+
+func (i *AuditIssue) String() string {
+	return fmt.Sprintf("%v %v %v %v %v", i.Symbol, i.Dataset, i.Date.Format(tickfilelayout), i.Type, i.Details)
+}
+
+type AuditReport struct {
+	Symbols []string
+	Issues  []AuditIssue
+}
+
+func (r *AuditReport) Count(t AuditIssueType) int {
+	count := 0
+	for _, i := range r.Issues {
+		if i.Type == t {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *AuditReport) SymbolIssues(symbol string) []AuditIssue {
+	var issues []AuditIssue
+	for _, i := range r.Issues {
+		if i.Symbol == symbol {
+			issues = append(issues, i)
+		}
+	}
+	return issues
+}
+
+func (r *AuditReport) String() string {
+	lines := []string{fmt.Sprintf("%v symbols audited, %v issues found", len(r.Symbols), len(r.Issues))}
+	for _, i := range r.Issues {
+		lines = append(lines, i.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+type AuditOptions struct {
+	PriceTolerance  float64     // Relative difference of daily High/Low and aggregated trades which is still fine
+	VolumeTolerance float64     // Relative difference of volumes. Volumes aren't compared if it's 0
+	Holidays        []time.Time // Days which are not reported as calendar gaps
+}
+
+func DefaultAuditOptions() AuditOptions {
+	return AuditOptions{0.005, 0, nil}
+}
+
+func dateKey(t time.Time) string {
+	return t.Format(tickfilelayout)
+}
+
+// Audits stored data of given symbols. If symbols aren't set all symbols found in storage are audited. Options are
+// not discovered automatically, they should be passed explicitly.
+func (p *JsonStorage) Audit(options AuditOptions, symbols ...string) (*AuditReport, error) {
+	if len(symbols) == 0 {
+		var err error
+		symbols, err = p.StoredSymbols()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := AuditReport{Symbols: symbols}
+	for _, s := range symbols {
+		issues, err := p.auditSymbol(s, &options)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Audit() Symbol: %v", s)
+		}
+		report.Issues = append(report.Issues, issues...)
+	}
+
+	return &report, nil
+}
+
+// Symbols which have daily candles or ticks in storage
+func (p *JsonStorage) StoredSymbols() ([]string, error) {
+	set := make(map[string]struct{})
+
+	candlesDir := path.Join(p.Path, "candles", "day")
+	if fileExists(candlesDir) {
+		files, err := ioutil.ReadDir(candlesDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			if !f.IsDir() && strings.HasSuffix(f.Name(), ".json") {
+				set[strings.TrimSuffix(f.Name(), ".json")] = struct{}{}
+			}
+		}
+	}
+
+	for _, folder := range p.tickFolders() {
+		files, err := ioutil.ReadDir(path.Join(p.Path, "ticks", folder))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			if f.IsDir() && !strings.HasPrefix(f.Name(), ".") {
+				set[f.Name()] = struct{}{}
+			}
+		}
+	}
+
+	var symbols []string
+	for s := range set {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+	return symbols, nil
+}
+
+// Existing tick folders: quotes, trades and quotes_trades
+func (p *JsonStorage) tickFolders() []string {
+	var folders []string
+	for _, f := range []string{p.generateTicksFolderName(true, false), p.generateTicksFolderName(false, true),
+		p.generateTicksFolderName(true, true)} {
+		if fileExists(path.Join(p.Path, "ticks", f)) {
+			folders = append(folders, f)
+		}
+	}
+	return folders
+}
+
+func (p *JsonStorage) auditSymbol(symbol string, options *AuditOptions) ([]AuditIssue, error) {
+	var issues []AuditIssue
+
+	var daily CandleArray
+	candlesPath := path.Join(p.Path, "candles", "day", symbolStoragePath(symbol)+".json")
+	if fileExists(candlesPath) {
+		var err error
+		daily, err = p.readCandlesFromFile(candlesPath)
+		if err != nil {
+			issues = append(issues, AuditIssue{symbol, IssueUnreadableFile, "candles/day", time.Time{}, err.Error()})
+		} else {
+			issues = append(issues, p.auditCandles(symbol, daily, options)...)
+		}
+	}
+
+	tradesByDay := make(map[string]TickArray)
+	for _, folder := range p.tickFolders() {
+		folderIssues, err := p.auditTicks(symbol, folder, options, tradesByDay)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, folderIssues...)
+	}
+
+	rollHour := 0
+	if p.marketMode() == AllDaysMarket {
+		rollHour = p.DailyRollHour
+	}
+	issues = append(issues, compareDailyWithTicks(symbol, daily, tradesByDay, rollHour, options)...)
+
+	return issues, nil
+}
+
+func (p *JsonStorage) auditCandles(symbol string, candles CandleArray, options *AuditOptions) []AuditIssue {
+	dataset := "candles/day"
+	var issues []AuditIssue
+	issue := func(t AuditIssueType, d time.Time, details string) {
+		issues = append(issues, AuditIssue{symbol, t, dataset, d, details})
+	}
+
+	seen := make(map[int64]struct{})
+	var dates []time.Time
+	for _, c := range candles {
+		if c.High < c.Low {
+			issue(IssueBadCandle, c.Datetime, fmt.Sprintf("High %v < Low %v", c.High, c.Low))
+		} else if c.Open < c.Low || c.Open > c.High || c.Close < c.Low || c.Close > c.High {
+			issue(IssueBadCandle, c.Datetime, fmt.Sprintf("Open %v or Close %v outside of %v - %v", c.Open, c.Close,
+				c.Low, c.High))
+		}
+
+		if c.Volume == 0 {
+			issue(IssueZeroVolume, c.Datetime, "")
+		}
+
+		key := c.Datetime.UnixNano()
+		if _, ok := seen[key]; ok {
+			issue(IssueDuplicateTimestamp, c.Datetime, "")
+			continue
+		}
+		seen[key] = struct{}{}
+		dates = append(dates, c.Datetime)
+	}
+
+	for _, d := range p.calendarGaps(dates, options) {
+		issue(IssueCalendarGap, d, "")
+	}
+
+	return issues
+}
+
+// Checks tick files of symbol in folder. Trades of every day are collected for comparison with daily candles
+func (p *JsonStorage) auditTicks(symbol string, folder string, options *AuditOptions,
+	tradesByDay map[string]TickArray) ([]AuditIssue, error) {
+
+	dataset := path.Join("ticks", folder)
+	symbolFolder := path.Join(p.Path, dataset, symbolStoragePath(symbol))
+	if !fileExists(symbolFolder) {
+		return nil, nil
+	}
+
+	var issues []AuditIssue
+	issue := func(t AuditIssueType, d time.Time, details string) {
+		issues = append(issues, AuditIssue{symbol, t, dataset, d, details})
+	}
+
+	fileDates, err := p.getStoredTickDates(symbolFolder)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]struct{})
+	for _, d := range fileDates {
+		files[dateKey(d)] = struct{}{}
+	}
+
+	metaPath := path.Join(p.Path, dataset, ".meta", symbolStoragePath(symbol)+".json")
+	if fileExists(metaPath) {
+		meta := JsonSymbolMeta{}
+		err = meta.Load(metaPath)
+		if err != nil {
+			// Listed dates are unknown, so files aren't compared with meta
+			issue(IssueUnreadableFile, time.Time{}, fmt.Sprintf("meta: %v", err))
+		} else {
+			listed := make(map[string]struct{})
+			for _, d := range meta.ListedDates {
+				listed[dateKey(d)] = struct{}{}
+				if _, ok := files[dateKey(d)]; !ok {
+					issue(IssueMissingFile, d, "")
+				}
+			}
+			for _, d := range fileDates {
+				if _, ok := listed[dateKey(d)]; !ok {
+					issue(IssueUnlistedFile, d, "")
+				}
+			}
+		}
+	}
+
+	for _, d := range fileDates {
+		ticks, err := p.readTicksFromFile(path.Join(symbolFolder, dateKey(d)+".json"))
+		if err != nil {
+			issue(IssueUnreadableFile, d, err.Error())
+			continue
+		}
+		if len(*ticks) == 0 {
+			issue(IssueEmptyTickDay, d, "")
+			continue
+		}
+
+		_, duplicates := (&DuplicateTickFilter{}).Apply(*ticks)
+		if len(duplicates) > 0 {
+			issue(IssueDuplicateTimestamp, d, fmt.Sprintf("%v repeated ticks", len(duplicates)))
+		}
+
+		if _, ok := tradesByDay[dateKey(d)]; !ok && folder != p.generateTicksFolderName(true, false) {
+			tradesByDay[dateKey(d)] = *ticks
+		}
+	}
+
+	for _, d := range p.calendarGaps(fileDates, options) {
+		issue(IssueCalendarGap, d, "")
+	}
+
+	return issues, nil
+}
+
+// Trading days between the first and the last date which are not in dates
+func (p *JsonStorage) calendarGaps(dates []time.Time, options *AuditOptions) []time.Time {
+	if len(dates) == 0 {
+		return nil
+	}
+
+	sorted := make([]time.Time, len(dates))
+	copy(sorted, dates)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Before(sorted[j])
+	})
+
+	present := make(map[string]struct{})
+	for _, d := range sorted {
+		present[dateKey(d)] = struct{}{}
+	}
+	for _, d := range options.Holidays {
+		present[dateKey(d)] = struct{}{}
+	}
+
+	var gaps []time.Time
+	last := sorted[len(sorted)-1]
+	for d := setTimeToSOD(sorted[0]); !d.After(last); d = d.AddDate(0, 0, 1) {
+		if !p.marketMode().isTradingDay(d) {
+			continue
+		}
+		if _, ok := present[dateKey(d)]; !ok {
+			gaps = append(gaps, d)
+		}
+	}
+	return gaps
+}
+
+// Day rolls at rollHour UTC. Day which starts before midnight takes trades of two tick files, so it's compared
+// only when both files are stored
+func compareDailyWithTicks(symbol string, daily CandleArray, tradesByDay map[string]TickArray, rollHour int,
+	options *AuditOptions) []AuditIssue {
+
+	rolled := make(map[string]*Candle)
+	if rollHour > 0 {
+		var trades TickArray
+		for _, ticks := range tradesByDay {
+			trades = append(trades, ticks...)
+		}
+		trades.Sort()
+		for _, c := range AggregateDailyTicks(trades, rollHour) {
+			rolled[dateKey(c.Datetime)] = c
+		}
+	}
+
+	var issues []AuditIssue
+	for _, c := range daily {
+		key := dateKey(c.Datetime)
+		ticks, ok := tradesByDay[key]
+		if !ok {
+			continue
+		}
+
+		var a *Candle
+		if rollHour == 0 {
+			aggregated := AggregateDailyTicks(ticks, 0)
+			if len(aggregated) == 0 {
+				continue
+			}
+			a = aggregated[0]
+		} else {
+			if _, ok := tradesByDay[dateKey(c.Datetime.AddDate(0, 0, -1))]; !ok {
+				continue
+			}
+			if a, ok = rolled[key]; !ok {
+				continue
+			}
+		}
+
+		var diffs []string
+		if relativeDiff(c.High, a.High) > options.PriceTolerance {
+			diffs = append(diffs, fmt.Sprintf("High %v != %v", c.High, a.High))
+		}
+		if relativeDiff(c.Low, a.Low) > options.PriceTolerance {
+			diffs = append(diffs, fmt.Sprintf("Low %v != %v", c.Low, a.Low))
+		}
+		if options.VolumeTolerance > 0 &&
+			relativeDiff(float64(c.Volume), float64(a.Volume)) > options.VolumeTolerance {
+			diffs = append(diffs, fmt.Sprintf("Volume %v != %v", c.Volume, a.Volume))
+		}
+
+		if len(diffs) > 0 {
+			issues = append(issues, AuditIssue{symbol, IssueCandleTickMismatch, "candles/day", c.Datetime,
+				strings.Join(diffs, ", ")})
+		}
+	}
+	return issues
+}
+
+func relativeDiff(expected float64, actual float64) float64 {
+	if expected == 0 {
+		return math.Abs(actual)
+	}
+	return math.Abs(expected-actual) / math.Abs(expected)
+}
@@ -0,0 +1,170 @@
+package marketdata
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJsonStorage_Audit(t *testing.T) {
+	testDir := "./test_data/audit"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC, Market: WeekdaysMarket}
+
+	// Thursday 2018-11-01 - Wednesday 2018-11-07. 2018-11-06 is missing
+	candles := CandleArray{
+		{"SPY", 10, 11, 9, 10.5, 10.5, 100, 0, timeOnTheFly(2018, 11, 1), "", nil},
+		{"SPY", 10, 9, 11, 10.5, 10.5, 100, 0, timeOnTheFly(2018, 11, 2), "", nil},
+		{"SPY", 12, 11, 9, 10.5, 10.5, 0, 0, timeOnTheFly(2018, 11, 5), "", nil},
+		{"SPY", 10, 11, 9, 10.5, 10.5, 100, 0, timeOnTheFly(2018, 11, 5), "", nil},
+		{"SPY", 10, 11, 9, 10.5, 10.5, 100, 0, timeOnTheFly(2018, 11, 7), "", nil},
+	}
+	err := storage.saveCandlesToFile(&candles, path.Join(testDir, "candles/day/SPY.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ticksFolder := path.Join(testDir, "ticks/quotes_trades/SPY")
+	day1 := TickArray{
+		{Symbol: "SPY", LastPrice: 10.9, LastSize: 100, Datetime: time.Date(2018, 11, 1, 10, 0, 0, 0, time.UTC)},
+		{Symbol: "SPY", LastPrice: 9.5, LastSize: 100, Datetime: time.Date(2018, 11, 1, 11, 0, 0, 0, time.UTC)},
+		{Symbol: "SPY", LastPrice: 9.5, LastSize: 100, Datetime: time.Date(2018, 11, 1, 11, 0, 0, 0, time.UTC)},
+	}
+	var empty TickArray
+	for _, f := range []struct {
+		day   time.Time
+		ticks *TickArray
+	}{{timeOnTheFly(2018, 11, 1), &day1}, {timeOnTheFly(2018, 11, 5), &empty}} {
+		err = storage.saveTicksToFile(f.ticks, path.Join(ticksFolder, f.day.Format(tickfilelayout)+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	meta := JsonSymbolMeta{Symbol: "SPY", ListedDates: []time.Time{timeOnTheFly(2018, 11, 1),
+		timeOnTheFly(2018, 11, 2)}}
+	err = meta.save(path.Join(testDir, "ticks/quotes_trades/.meta/SPY.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	symbols, err := storage.StoredSymbols()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"SPY"}, symbols)
+
+	report, err := storage.Audit(DefaultAuditOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 2, report.Count(IssueBadCandle))
+	assert.Equal(t, 1, report.Count(IssueZeroVolume))
+	// Duplicate candle and repeated tick
+	assert.Equal(t, 2, report.Count(IssueDuplicateTimestamp))
+	assert.Equal(t, 1, report.Count(IssueMissingFile))
+	assert.Equal(t, 1, report.Count(IssueUnlistedFile))
+	assert.Equal(t, 1, report.Count(IssueEmptyTickDay))
+	assert.Equal(t, 1, report.Count(IssueCandleTickMismatch))
+	// 2018-11-06 in candles, 2018-11-02 in ticks
+	assert.Equal(t, 2, report.Count(IssueCalendarGap))
+	assert.Equal(t, len(report.Issues), len(report.SymbolIssues("SPY")))
+
+	options := DefaultAuditOptions()
+	options.Holidays = []time.Time{timeOnTheFly(2018, 11, 6), timeOnTheFly(2018, 11, 2)}
+	report, err = storage.Audit(options, "SPY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, report.Count(IssueCalendarGap))
+}
+
+func TestJsonStorage_AuditDailyRollHour(t *testing.T) {
+	testDir := "./test_data/audit_roll_hour"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC, Market: AllDaysMarket, DailyRollHour: 22}
+
+	// Candle of 2018-11-02 starts at 2018-11-01 22:00 UTC
+	candles := CandleArray{
+		{"SPY", 20, 21, 20, 21, 21, 200, 0, timeOnTheFly(2018, 11, 2), "", nil},
+	}
+	err := storage.saveCandlesToFile(&candles, path.Join(testDir, "candles/day/SPY.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ticksFolder := path.Join(testDir, "ticks/quotes_trades/SPY")
+	days := map[time.Time]TickArray{
+		timeOnTheFly(2018, 11, 1): {
+			{Symbol: "SPY", LastPrice: 10, LastSize: 100, Datetime: time.Date(2018, 11, 1, 12, 0, 0, 0, time.UTC)},
+			{Symbol: "SPY", LastPrice: 20, LastSize: 100, Datetime: time.Date(2018, 11, 1, 23, 0, 0, 0, time.UTC)},
+		},
+		timeOnTheFly(2018, 11, 2): {
+			{Symbol: "SPY", LastPrice: 21, LastSize: 100, Datetime: time.Date(2018, 11, 2, 12, 0, 0, 0, time.UTC)},
+		},
+	}
+	for day, ticks := range days {
+		ticks := ticks
+		err = storage.saveTicksToFile(&ticks, path.Join(ticksFolder, day.Format(tickfilelayout)+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := storage.Audit(DefaultAuditOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, report.Count(IssueCandleTickMismatch))
+
+	// The same candle doesn't match day rolled at midnight
+	storage.DailyRollHour = 0
+	report, err = storage.Audit(DefaultAuditOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, report.Count(IssueCandleTickMismatch))
+}
+
+func TestJsonStorage_AuditUnreadableFiles(t *testing.T) {
+	testDir := "./test_data/audit_unreadable"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC, Market: WeekdaysMarket}
+
+	ticksFolder := path.Join(testDir, "ticks/quotes_trades")
+	for _, f := range []string{
+		"candles/day/SPY.json",
+		"ticks/quotes_trades/SPY/2018-11-01.json",
+		"ticks/quotes_trades/.meta/SPY.json",
+	} {
+		err := writeFileAtomic(path.Join(testDir, f), []byte("{broken"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	ticks := TickArray{
+		{Symbol: "SPY", LastPrice: 10, LastSize: 100, Datetime: time.Date(2018, 11, 2, 12, 0, 0, 0, time.UTC)},
+	}
+	err := storage.saveTicksToFile(&ticks, path.Join(ticksFolder, "SPY", "2018-11-02.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := storage.Audit(DefaultAuditOptions(), "SPY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, report.Count(IssueUnreadableFile))
+	assert.Equal(t, 0, report.Count(IssueUnlistedFile))
+	assert.Equal(t, 3, len(report.Issues))
+}
@@ -0,0 +1,145 @@
+package marketdata
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const DefaultSubscriptionBuffer = 1024
+
+// Source of live ticks for streaming services
+type LiveTickSource interface {
+	// Subscribes To ticks of given symbols. All symbols are sent if symbols aren't set
+	Subscribe(symbols ...string) *TickSubscription
+	// The last trade and quote of symbol
+	Snapshot(symbol string) (*TickSnapshot, bool)
+}
+
+type TickSnapshot struct {
+	Symbol    string
+	LastTrade *Tick
+	LastQuote *Tick
+	Updated   time.Time
+}
+
+type TickSubscription struct {
+	C       <-chan *Tick
+	c       chan *Tick
+	symbols map[string]struct{}
+	dropped int64
+	closed  bool
+	owner   *TickBroadcaster
+}
+
+// Number of ticks which were dropped because subscriber didn't read them in time
+func (s *TickSubscription) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+// Unsubscribes and closes channel C
+func (s *TickSubscription) Close() {
+	s.owner.unsubscribe(s)
+}
+
+func (s *TickSubscription) wants(symbol string) bool {
+	if len(s.symbols) == 0 {
+		return true
+	}
+	_, ok := s.symbols[symbol]
+	return ok
+}
+
+// In-process fan-out of live ticks. Publisher is never blocked by slow subscriber: ticks which don't fit into
+// subscriber buffer are dropped and counted
+type TickBroadcaster struct {
+	Buffer int // Channel buffer of every subscription. 0 means DefaultSubscriptionBuffer
+
+	mu        sync.RWMutex
+	subs      map[*TickSubscription]struct{}
+	snapshots map[string]*TickSnapshot
+}
+
+func NewTickBroadcaster() *TickBroadcaster {
+	return &TickBroadcaster{
+		subs:      make(map[*TickSubscription]struct{}),
+		snapshots: make(map[string]*TickSnapshot),
+	}
+}
+
+func (b *TickBroadcaster) Subscribe(symbols ...string) *TickSubscription {
+	buffer := b.Buffer
+	if buffer <= 0 {
+		buffer = DefaultSubscriptionBuffer
+	}
+
+	c := make(chan *Tick, buffer)
+	sub := TickSubscription{C: c, c: c, symbols: make(map[string]struct{}), owner: b}
+	for _, s := range symbols {
+		sub.symbols[s] = struct{}{}
+	}
+
+	b.mu.Lock()
+	b.subs[&sub] = struct{}{}
+	b.mu.Unlock()
+	return &sub
+}
+
+func (b *TickBroadcaster) unsubscribe(s *TickSubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	delete(b.subs, s)
+	close(s.c)
+}
+
+// Sends tick To all subscribers of its symbol and updates snapshot
+func (b *TickBroadcaster) Publish(tick *Tick) {
+	b.mu.Lock()
+	snap, ok := b.snapshots[tick.Symbol]
+	if !ok {
+		snap = &TickSnapshot{Symbol: tick.Symbol}
+		b.snapshots[tick.Symbol] = snap
+	}
+	if tick.HasTrade() {
+		snap.LastTrade = tick
+	}
+	if tick.isQuoteEvent() {
+		snap.LastQuote = tick
+	}
+	snap.Updated = time.Now()
+	b.mu.Unlock()
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for s := range b.subs {
+		if !s.wants(tick.Symbol) {
+			continue
+		}
+		select {
+		case s.c <- tick:
+		default:
+			atomic.AddInt64(&s.dropped, 1)
+		}
+	}
+}
+
+func (b *TickBroadcaster) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+func (b *TickBroadcaster) Snapshot(symbol string) (*TickSnapshot, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	snap, ok := b.snapshots[symbol]
+	if !ok {
+		return nil, false
+	}
+	copied := *snap
+	return &copied, true
+}
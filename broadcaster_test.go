@@ -0,0 +1,42 @@
+package marketdata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTickBroadcaster(t *testing.T) {
+	b := NewTickBroadcaster()
+	b.Buffer = 2
+
+	spy := b.Subscribe("SPY")
+	all := b.Subscribe()
+	assert.Equal(t, 2, b.Subscribers())
+
+	b.Publish(tradeAt(0, 10))
+	b.Publish(&Tick{Symbol: "QQQ", LastPrice: 1, LastSize: 1})
+	b.Publish(quoteAt(1, 10, 10.01))
+
+	assert.Equal(t, 10.0, (<-spy.C).LastPrice)
+	assert.Equal(t, 10.01, (<-spy.C).AskPrice)
+	assert.Equal(t, int64(0), spy.Dropped())
+
+	// Slow subscriber loses ticks which don't fit into buffer
+	assert.Equal(t, "SPY", (<-all.C).Symbol)
+	assert.Equal(t, "QQQ", (<-all.C).Symbol)
+	assert.Equal(t, int64(1), all.Dropped())
+
+	snap, ok := b.Snapshot("SPY")
+	assert.True(t, ok)
+	assert.Equal(t, 10.0, snap.LastTrade.LastPrice)
+	assert.Equal(t, 10.01, snap.LastQuote.AskPrice)
+	_, ok = b.Snapshot("AAPL")
+	assert.False(t, ok)
+
+	spy.Close()
+	spy.Close()
+	_, open := <-spy.C
+	assert.False(t, open)
+	assert.Equal(t, 1, b.Subscribers())
+}
@@ -0,0 +1,190 @@
+package marketdata
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Storage which is able To download missing data and persist it
+type UpdatableStorage interface {
+	Storage
+	UpdateSymbolCandles(params CandlesUpdateParams) error
+	UpdateSymbolTicks(params TickUpdateParams) error
+}
+
+// CachingProvider is read-through cache. Covered dates are served From storage, gaps are downloaded and persisted
+// by storage itself. Current date can't be stored, so it's always requested From Upstream directly. Daily candles are
+// cached up To yesterday, other timeframes go To Upstream.
+//
+// Ticks are always cached for full days. Don't mix it with TickUpdateParams requests for particular time of day
+// in the same storage.
+type CachingProvider struct {
+	Storage  UpdatableStorage
+	Upstream HistoryProvider
+	TimeZone *time.Location
+}
+
+func NewCachingProvider(storage *JsonStorage) *CachingProvider {
+	return &CachingProvider{
+		Storage:  storage,
+		Upstream: storage.Provider,
+		TimeZone: storage.TimeZone,
+	}
+}
+
+func (c *CachingProvider) GetCandles(symbol string, timeframe string, dRange DateRange) (CandleArray, error) {
+	if timeframe != "D" {
+		return c.Upstream.GetCandles(symbol, timeframe, dRange)
+	}
+
+	// Daily candles are dated by UTC midnight, today is the date in provider time zone
+	now := time.Now().In(c.location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	fromDay := time.Date(dRange.From.Year(), dRange.From.Month(), dRange.From.Day(), 0, 0, 0, 0, time.UTC)
+
+	var candles CandleArray
+
+	if fromDay.Before(today) {
+		rng := DateRange{dRange.From, dRange.To}
+		if !rng.To.Before(today) {
+			rng.To = today.AddDate(0, 0, -1)
+		}
+		stored, err := c.getStoredCandles(symbol, rng)
+		if err != nil {
+			return nil, err
+		}
+		candles = append(candles, stored...)
+	}
+
+	if !dRange.To.Before(today) {
+		rng := DateRange{dRange.From, dRange.To}
+		if rng.From.Before(today) {
+			rng.From = today
+		}
+
+		fresh, err := c.Upstream.GetCandles(symbol, timeframe, rng)
+		if err != nil {
+			if _, ok := errors.Cause(err).(*ErrEmptyResponse); !ok {
+				return nil, err
+			}
+		}
+		candles = append(candles, fresh...)
+	}
+
+	var filtered CandleArray
+	for _, candle := range candles {
+		if candle.Datetime.Before(fromDay) || candle.Datetime.After(dRange.To) {
+			continue
+		}
+		filtered = append(filtered, candle)
+	}
+
+	filtered.Sort()
+
+	return filtered, nil
+}
+
+func (c *CachingProvider) getStoredCandles(symbol string, dRange DateRange) (CandleArray, error) {
+	params := CandlesUpdateParams{
+		Symbol:    symbol,
+		TimeFrame: "D",
+		FromDate:  dRange.From,
+		ToDate:    dRange.To,
+	}
+
+	err := c.Storage.UpdateSymbolCandles(params)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := c.Storage.GetStoredCandles(symbol, "D", dRange)
+	if err != nil {
+		return nil, err
+	}
+
+	var candles CandleArray
+	for _, candle := range stored {
+		if !candle.Datetime.After(dRange.To) {
+			candles = append(candles, candle)
+		}
+	}
+	return candles, nil
+}
+
+func (c *CachingProvider) GetTicks(symbol string, dRange DateRange, quotes bool, trades bool) (TickArray, error) {
+	loc := c.location()
+	today := setTimeToSOD(time.Now().In(loc))
+	fromDay := time.Date(dRange.From.Year(), dRange.From.Month(), dRange.From.Day(), 0, 0, 0, 0, loc)
+
+	var ticks TickArray
+
+	if fromDay.Before(today) {
+		stored, err := c.getStoredTicks(symbol, dRange, quotes, trades)
+		if err != nil {
+			return nil, err
+		}
+		ticks = append(ticks, stored...)
+	}
+
+	if !dRange.To.Before(today) {
+		rng := DateRange{dRange.From, dRange.To}
+		if rng.From.Before(today) {
+			rng.From = today
+		}
+
+		fresh, err := c.Upstream.GetTicks(symbol, rng, quotes, trades)
+		if err != nil {
+			if _, ok := errors.Cause(err).(*ErrEmptyResponse); !ok {
+				return nil, err
+			}
+		}
+		ticks = append(ticks, fresh...)
+	}
+
+	var filtered TickArray
+	for _, t := range ticks {
+		if t.Datetime.Before(dRange.From) || t.Datetime.After(dRange.To) {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+
+	filtered.Sort()
+
+	return filtered, nil
+}
+
+func (c *CachingProvider) getStoredTicks(symbol string, dRange DateRange, quotes bool, trades bool) (TickArray, error) {
+	params := TickUpdateParams{
+		Symbol:    symbol,
+		FromDate:  dRange.From,
+		ToDate:    dRange.To,
+		StartTime: TimeOfDay{},
+		EndTime:   TimeOfDay{23, 59, 59},
+		Quotes:    quotes,
+		Trades:    trades,
+	}
+
+	err := c.Storage.UpdateSymbolTicks(params)
+	if err != nil {
+		if _, ok := errors.Cause(err).(*ErrNothingToDownload); !ok {
+			return nil, err
+		}
+	}
+
+	// UpdateSymbolTicks cuts range To yesterday, storage range should be the same
+	err = params.modifyTimes(c.location())
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Storage.GetStoredTicks(symbol, DateRange{params.FromDate, params.ToDate}, quotes, trades)
+}
+
+func (c *CachingProvider) location() *time.Location {
+	if c.TimeZone == nil {
+		return time.UTC
+	}
+	return c.TimeZone
+}
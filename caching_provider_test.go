@@ -0,0 +1,183 @@
+package marketdata
+
+import (
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns one trade at 10:00 for every requested day
+type dailyTicksMock struct {
+	mu        sync.Mutex
+	requested []time.Time
+}
+
+func (p *dailyTicksMock) GetCandles(symbol string, timeframe string, dRange DateRange) (CandleArray, error) {
+	return nil, &ErrEmptyResponse{"candles"}
+}
+
+func (p *dailyTicksMock) GetTicks(symbol string, dRange DateRange, quotes bool, trades bool) (TickArray, error) {
+	p.mu.Lock()
+	p.requested = append(p.requested, dRange.From)
+	p.mu.Unlock()
+
+	d := dRange.From
+	tick := Tick{
+		Symbol:    symbol,
+		LastPrice: 10,
+		LastSize:  100,
+		Datetime:  time.Date(d.Year(), d.Month(), d.Day(), 10, 0, 0, 0, time.UTC),
+	}
+	return TickArray{&tick}, nil
+}
+
+func TestCachingProvider_GetTicks(t *testing.T) {
+	testDir := "./test_data/caching_provider"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	upstream := &dailyTicksMock{}
+	storage := JsonStorage{
+		2,
+		testDir,
+		upstream,
+		time.UTC,
+		true,
+		nil,
+		nil,
+		false,
+		"",
+		0,
+		nil,
+		nil,
+		0,
+	}
+	provider := NewCachingProvider(&storage)
+
+	// Monday - Wednesday
+	rng := DateRange{timeOnTheFly(2018, 11, 5), time.Date(2018, 11, 7, 23, 0, 0, 0, time.UTC)}
+	ticks, err := provider.GetTicks("SPY", rng, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 3, len(ticks))
+	assert.Equal(t, 3, len(upstream.requested))
+
+	// Monday - Friday. Only Thursday and Friday should be downloaded, range is cut by time
+	rng = DateRange{timeOnTheFly(2018, 11, 5), time.Date(2018, 11, 9, 9, 0, 0, 0, time.UTC)}
+	ticks, err = provider.GetTicks("SPY", rng, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 4, len(ticks))
+	assert.Equal(t, 5, len(upstream.requested))
+	assert.True(t, ticks[3].Datetime.Equal(time.Date(2018, 11, 8, 10, 0, 0, 0, time.UTC)))
+
+	// Today is never stored and always requested From upstream
+	now := time.Now().UTC()
+	rng = DateRange{setTimeToSOD(now), now.Add(time.Hour)}
+	_, err = provider.GetTicks("SPY", rng, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 6, len(upstream.requested))
+}
+
+// Returns candle for every requested day and records requested ranges
+type dailyCandlesMock struct {
+	dailyTicksMock
+	candleRequests []DateRange
+}
+
+func (p *dailyCandlesMock) GetCandles(symbol string, timeframe string, dRange DateRange) (CandleArray, error) {
+	p.candleRequests = append(p.candleRequests, dRange)
+	var candles CandleArray
+	for d := setTimeToSOD(dRange.From); !d.After(dRange.To); d = d.AddDate(0, 0, 1) {
+		candles = append(candles, &Candle{Symbol: symbol, Open: 10, High: 11, Low: 9, Close: 10, AdjClose: 10,
+			Volume: 100, Datetime: d})
+	}
+	return candles, nil
+}
+
+func TestCachingProvider_GetCandles(t *testing.T) {
+	testDir := "./test_data/caching_provider_candles"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	upstream := &dailyCandlesMock{}
+	storage := JsonStorage{Path: testDir, Provider: upstream, TimeZone: time.UTC, Market: WeekdaysMarket}
+	provider := NewCachingProvider(&storage)
+
+	rng := DateRange{timeOnTheFly(2018, 11, 5), timeOnTheFly(2018, 11, 9)}
+	candles, err := provider.GetCandles("SPY", "D", rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 5, len(candles))
+	assert.Equal(t, 1, len(upstream.candleRequests))
+
+	// The same range is served From storage
+	candles, err = provider.GetCandles("SPY", "D", rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 5, len(candles))
+	assert.Equal(t, 1, len(upstream.candleRequests))
+
+	// Only missing days are downloaded
+	rng = DateRange{timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 12)}
+	candles, err = provider.GetCandles("SPY", "D", rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 12, len(candles))
+	assert.Equal(t, []DateRange{
+		{timeOnTheFly(2018, 11, 5), timeOnTheFly(2018, 11, 9)},
+		{timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 4)},
+		{timeOnTheFly(2018, 11, 10), timeOnTheFly(2018, 11, 12)},
+	}, upstream.candleRequests)
+}
+
+func TestCachingProvider_GetCandlesToday(t *testing.T) {
+	testDir := "./test_data/caching_provider_today"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	upstream := &dailyCandlesMock{}
+	storage := JsonStorage{Path: testDir, Provider: upstream, TimeZone: time.UTC, Market: AllDaysMarket}
+	provider := NewCachingProvider(&storage)
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	rng := DateRange{today.AddDate(0, 0, -3), today.AddDate(0, 0, 1)}
+	candles, err := provider.GetCandles("BTCUSD", "D", rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 5, len(candles))
+
+	// Today and tomorrow are requested From upstream and aren't stored
+	assert.Equal(t, []DateRange{
+		{today.AddDate(0, 0, -3), today.AddDate(0, 0, -1)},
+		{today, today.AddDate(0, 0, 1)},
+	}, upstream.candleRequests)
+	stored, err := storage.GetStoredCandles("BTCUSD", "D", DateRange{})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(stored))
+	meta := loadMetaIfExists(path.Join(testDir, "candles/day/.meta/BTCUSD.json"))
+	last, _ := meta.lastDate()
+	assert.Equal(t, today.AddDate(0, 0, -1), last)
+
+	// Storage itself never lists future days
+	err = storage.UpdateSymbolCandles(CandlesUpdateParams{Symbol: "ETHUSD", TimeFrame: "D",
+		FromDate: today.AddDate(0, 0, -1), ToDate: today.AddDate(0, 0, 5)})
+	assert.Nil(t, err)
+	meta = loadMetaIfExists(path.Join(testDir, "candles/day/.meta/ETHUSD.json"))
+	assert.Equal(t, []time.Time{today.AddDate(0, 0, -1)}, meta.ListedDates)
+}
@@ -0,0 +1,112 @@
+package marketdata
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+)
+
+type ErrCassetteEntryNotFound struct {
+	uri  string
+	path string
+}
+
+func (e *ErrCassetteEntryNotFound) Error() string {
+	return fmt.Sprintf("Request %v is not recorded in cassette: %v", e.uri, e.path)
+}
+
+// Single recorded response of datasource
+type CassetteEntry struct {
+	URI  string
+	Code int
+	Body string
+}
+
+// Cassette is a folder with raw datasource responses. Every request URI is stored in separate file, so
+// cassettes can be recorded once against live datasource and replayed later without network.
+type Cassette struct {
+	Path string
+}
+
+func NewCassette(pth string) *Cassette {
+	return &Cassette{pth}
+}
+
+func (c *Cassette) entryPath(uri string) string {
+	h := sha1.Sum([]byte(uri))
+	return filepath.Join(c.Path, hex.EncodeToString(h[:])+".json")
+}
+
+func (c *Cassette) Save(entry CassetteEntry) error {
+	err := createDirIfNotExists(c.Path)
+	if err != nil {
+		return err
+	}
+
+	json_, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(c.entryPath(entry.URI), json_, 0644)
+}
+
+func (c *Cassette) Load(uri string) (*CassetteEntry, error) {
+	pth := c.entryPath(uri)
+	if !fileExists(pth) {
+		return nil, &ErrCassetteEntryNotFound{uri, c.Path}
+	}
+
+	byteValue, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := CassetteEntry{}
+	err = json.Unmarshal(byteValue, &entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// Recording wrapper. Works as usual ActiveTick provider, but every raw response is written To cassette
+func NewRecordingActiveTick(at ActiveTick, cassettePath string) ActiveTick {
+	at.cassette = NewCassette(cassettePath)
+	at.replay = false
+	return at
+}
+
+// Replay provider. Never goes To network, all responses are taken From cassette recorded by
+// NewRecordingActiveTick
+func NewReplayActiveTick(cassettePath string, symbol_prefix string) ActiveTick {
+	at := NewActiveTick(0, "cassette", 0, symbol_prefix)
+	at.cassette = NewCassette(cassettePath)
+	at.replay = true
+	return at
+}
+
+// HTTP server which serves cassette responses the same way ActiveTick HTTP API does. Use it when you need real
+// endpoint, for example: NewActiveTick(port, "127.0.0.1", ...) in downstream tests. Not recorded requests get 404.
+// Caller should Close() server.
+func NewCassetteServer(cassettePath string) *httptest.Server {
+	cassette := NewCassette(cassettePath)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		entry, err := cassette.Load(r.URL.RequestURI())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(entry.Code)
+		w.Write([]byte(entry.Body))
+	}
+
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
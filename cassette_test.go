@@ -0,0 +1,61 @@
+package marketdata
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCassette_SaveLoad(t *testing.T) {
+	pth := "./test_data/cassette_test"
+	defer os.RemoveAll(pth)
+
+	cassette := NewCassette(pth)
+	entry := CassetteEntry{"/barData?symbol=SPY&historyType=1", 200, "20181101000000,1,2,0.5,1.5,10\r\n"}
+
+	err := cassette.Save(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := cassette.Load(entry.URI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, entry, *loaded)
+
+	_, err = cassette.Load("/barData?symbol=QQQ&historyType=1")
+	assert.IsType(t, &ErrCassetteEntryNotFound{}, err)
+}
+
+func TestActiveTick_RecordAndReplay(t *testing.T) {
+	pth := "./test_data/cassette_record_test"
+	defer os.RemoveAll(pth)
+
+	server := NewCassetteServer(testCassette)
+	defer server.Close()
+
+	// Record From cassette server as if it was live ActiveTick
+	live := NewActiveTick(0, server.Listener.Addr().String(), 0, "")
+	recorder := NewRecordingActiveTick(live, pth)
+
+	dRange := DateRange{time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC), time.Date(2018, 11, 10, 0, 0, 0, 0, time.UTC)}
+	recorded, err := recorder.GetCandles("SPY", "D", dRange)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replay := NewReplayActiveTick(pth, "")
+	replayed, err := replay.GetCandles("SPY", "D", dRange)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, recorded, replayed)
+
+	_, err = replay.GetCandles("SPY", "W", dRange)
+	assert.IsType(t, &ErrCassetteEntryNotFound{}, errors.Cause(err))
+}
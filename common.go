@@ -1,10 +1,15 @@
 package marketdata
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
+const tempFileSuffix = ".tmp"
+
 func fileExists(path string) bool {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return false
@@ -36,3 +41,60 @@ func setTimeToEOD(t time.Time) time.Time {
 	t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
 	return t
 }
+
+// Writes file atomically: data is written To temp file in the same folder, synced and renamed over the target. Readers
+// see either old or new file, never truncated one
+func writeFileAtomic(pth string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(pth)
+	err := createDirIfNotExists(dir)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(pth)+tempFileSuffix)
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(tmpName, perm)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpName, pth)
+	if err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// Makes rename durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	// Some file systems don't support sync of directories
+	d.Sync()
+	return nil
+}
+
+// Temp files of writeFileAtomic are hidden: .name.tmp123456
+func isTempFile(name string) bool {
+	return strings.HasPrefix(name, ".") && strings.Contains(name, tempFileSuffix)
+}
@@ -0,0 +1,207 @@
+package marketdata
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	tickTimeFrame = "Tick"
+)
+
+type ErrNoProviderAvailable struct {
+	symbol string
+	errs   []error
+}
+
+func (e *ErrNoProviderAvailable) Error() string {
+	if len(e.errs) == 0 {
+		return fmt.Sprintf("No available provider for %v", e.symbol)
+	}
+
+	var msgs []string
+	for _, err := range e.errs {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("All providers failed for %v: %v", e.symbol, strings.Join(msgs, "; "))
+}
+
+// ProviderRoute describes one provider in composite. If Symbols or TimeFrames are empty route accepts any
+// symbol or timeframe. Ticks are requested with "Tick" timeframe. Match can be used for more complex routing
+// (by asset class for example). It's checked after Symbols and TimeFrames.
+type ProviderRoute struct {
+	Name       string
+	Provider   HistoryProvider
+	Symbols    []string
+	TimeFrames []string
+	Match      func(symbol string, timeframe string) bool
+}
+
+func (r *ProviderRoute) accepts(symbol string, timeframe string) bool {
+	if len(r.Symbols) > 0 && !containsString(r.Symbols, symbol) {
+		return false
+	}
+
+	if len(r.TimeFrames) > 0 && !containsString(r.TimeFrames, timeframe) {
+		return false
+	}
+
+	if r.Match != nil && !r.Match(symbol, timeframe) {
+		return false
+	}
+
+	return true
+}
+
+// Health state of provider in composite
+type ProviderHealth struct {
+	Name      string
+	Failures  int // Consecutive failures
+	LastError error
+	OpenUntil time.Time // Circuit is open and provider is skipped until this time
+}
+
+// CompositeProvider is HistoryProvider which tries routed providers in given order. If provider fails it tries next
+// one. After FailureThreshold consecutive failures provider is skipped for Cooldown period. Empty response and wrong
+// request are final answers and aren't passed To next provider.
+type CompositeProvider struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+
+	routes []*ProviderRoute
+	health map[*ProviderRoute]*ProviderHealth // By route, so routes with the same Name don't share circuit
+	mu     sync.Mutex
+	now    func() time.Time
+}
+
+func NewCompositeProvider(failureThreshold int, cooldown time.Duration, routes ...ProviderRoute) *CompositeProvider {
+	c := CompositeProvider{
+		FailureThreshold: failureThreshold,
+		Cooldown:         cooldown,
+		health:           make(map[*ProviderRoute]*ProviderHealth),
+		now:              time.Now,
+	}
+
+	for i := range routes {
+		r := routes[i]
+		c.routes = append(c.routes, &r)
+		c.health[&r] = &ProviderHealth{Name: r.Name}
+	}
+
+	return &c
+}
+
+func (c *CompositeProvider) GetCandles(symbol string, timeframe string, dRange DateRange) (CandleArray, error) {
+	var errs []error
+
+	for _, r := range c.candidates(symbol, timeframe) {
+		candles, err := r.Provider.GetCandles(symbol, timeframe, dRange)
+		if !c.report(r, err) {
+			errs = append(errs, errors.Wrap(err, r.Name))
+			continue
+		}
+
+		for _, candle := range candles {
+			candle.Source = r.Name
+		}
+		return candles, err
+	}
+
+	return nil, &ErrNoProviderAvailable{symbol, errs}
+}
+
+func (c *CompositeProvider) GetTicks(symbol string, dRange DateRange, quotes bool, trades bool) (TickArray, error) {
+	var errs []error
+
+	for _, r := range c.candidates(symbol, tickTimeFrame) {
+		ticks, err := r.Provider.GetTicks(symbol, dRange, quotes, trades)
+		if !c.report(r, err) {
+			errs = append(errs, errors.Wrap(err, r.Name))
+			continue
+		}
+
+		for _, tick := range ticks {
+			tick.Source = r.Name
+		}
+		return ticks, err
+	}
+
+	return nil, &ErrNoProviderAvailable{symbol, errs}
+}
+
+// Returns copy of providers health states in routes order
+func (c *CompositeProvider) Health() []ProviderHealth {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var states []ProviderHealth
+	for _, r := range c.routes {
+		states = append(states, *c.health[r])
+	}
+	return states
+}
+
+func (c *CompositeProvider) candidates(symbol string, timeframe string) []*ProviderRoute {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	var routes []*ProviderRoute
+	for _, r := range c.routes {
+		if !r.accepts(symbol, timeframe) {
+			continue
+		}
+		if now.Before(c.health[r].OpenUntil) {
+			continue
+		}
+		routes = append(routes, r)
+	}
+
+	return routes
+}
+
+// Updates provider health. Returns true if result is final and shouldn't be requested From next provider
+func (c *CompositeProvider) report(r *ProviderRoute, err error) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h := c.health[r]
+
+	if err == nil || isFinalProviderError(err) {
+		h.Failures = 0
+		h.OpenUntil = time.Time{}
+		return true
+	}
+
+	h.Failures++
+	h.LastError = err
+	if c.FailureThreshold > 0 && h.Failures >= c.FailureThreshold {
+		h.OpenUntil = c.now().Add(c.Cooldown)
+	}
+
+	return false
+}
+
+func isFinalProviderError(err error) bool {
+	switch errors.Cause(err).(type) {
+	case *ErrEmptyResponse:
+		return true
+	case *ErrWrongRequest:
+		return true
+	default:
+		return false
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
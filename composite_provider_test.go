@@ -0,0 +1,135 @@
+package marketdata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type providerMock struct {
+	candles CandleArray
+	ticks   TickArray
+	err     error
+	calls   int
+}
+
+func (p *providerMock) GetCandles(symbol string, timeframe string, dRange DateRange) (CandleArray, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	var candles CandleArray
+	for _, c := range p.candles {
+		cc := *c
+		candles = append(candles, &cc)
+	}
+	return candles, nil
+}
+
+func (p *providerMock) GetTicks(symbol string, dRange DateRange, quotes bool, trades bool) (TickArray, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	var ticks TickArray
+	for _, t := range p.ticks {
+		tt := *t
+		ticks = append(ticks, &tt)
+	}
+	return ticks, nil
+}
+
+func TestCompositeProvider_Failover(t *testing.T) {
+	down := &providerMock{err: &ErrDatasourceNotConnected{"ActiveTick"}}
+	backup := &providerMock{
+		candles: CandleArray{{Symbol: "SPY", Close: 1}},
+		ticks:   TickArray{{Symbol: "SPY", LastPrice: 1}},
+	}
+
+	comp := NewCompositeProvider(2, time.Minute,
+		ProviderRoute{Name: "main", Provider: down},
+		ProviderRoute{Name: "backup", Provider: backup},
+	)
+	current := time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC)
+	comp.now = func() time.Time { return current }
+
+	candles, err := comp.GetCandles("SPY", "D", DateRange{})
+	assert.Nil(t, err)
+	assert.Equal(t, "backup", candles[0].Source)
+
+	ticks, err := comp.GetTicks("SPY", DateRange{}, true, true)
+	assert.Nil(t, err)
+	assert.Equal(t, "backup", ticks[0].Source)
+
+	// Circuit is open after 2 failures. Main provider shouldn't be requested
+	comp.GetCandles("SPY", "D", DateRange{})
+	assert.Equal(t, 2, down.calls)
+	assert.Equal(t, current.Add(time.Minute), comp.Health()[0].OpenUntil)
+
+	// After cooldown main provider is requested again
+	current = current.Add(2 * time.Minute)
+	down.err = nil
+	down.candles = CandleArray{{Symbol: "SPY", Close: 2}}
+
+	candles, err = comp.GetCandles("SPY", "D", DateRange{})
+	assert.Nil(t, err)
+	assert.Equal(t, "main", candles[0].Source)
+	assert.Equal(t, 0, comp.Health()[0].Failures)
+}
+
+func TestCompositeProvider_Routes(t *testing.T) {
+	daily := &providerMock{candles: CandleArray{{Symbol: "SPY"}}}
+	ticks := &providerMock{ticks: TickArray{{Symbol: "SPY"}}}
+
+	comp := NewCompositeProvider(1, time.Minute,
+		ProviderRoute{Name: "daily", Provider: daily, TimeFrames: []string{"D", "W"}},
+		ProviderRoute{Name: "ticks", Provider: ticks, Symbols: []string{"SPY"}, TimeFrames: []string{"Tick"}},
+	)
+
+	_, err := comp.GetTicks("SPY", DateRange{}, true, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, daily.calls)
+
+	_, err = comp.GetTicks("QQQ", DateRange{}, true, true)
+	assert.IsType(t, &ErrNoProviderAvailable{}, err)
+
+	_, err = comp.GetCandles("SPY", "30", DateRange{})
+	assert.IsType(t, &ErrNoProviderAvailable{}, err)
+}
+
+func TestCompositeProvider_FinalErrors(t *testing.T) {
+	empty := &providerMock{err: errors.Wrap(&ErrEmptyResponse{"/tickData"}, "getRawData")}
+	backup := &providerMock{}
+
+	comp := NewCompositeProvider(1, time.Minute,
+		ProviderRoute{Name: "main", Provider: empty},
+		ProviderRoute{Name: "backup", Provider: backup},
+	)
+
+	_, err := comp.GetTicks("SPY", DateRange{}, true, true)
+	assert.IsType(t, &ErrEmptyResponse{}, errors.Cause(err))
+	assert.Equal(t, 0, backup.calls)
+	assert.Equal(t, 0, comp.Health()[0].Failures)
+}
+
+func TestCompositeProvider_SameNames(t *testing.T) {
+	down := &providerMock{err: &ErrDatasourceNotConnected{"ActiveTick"}}
+	backup := &providerMock{candles: CandleArray{{Symbol: "SPY", Close: 1}}}
+
+	comp := NewCompositeProvider(1, time.Minute,
+		ProviderRoute{Provider: down},
+		ProviderRoute{Provider: backup},
+	)
+
+	// Failure of the first route doesn't open circuit of the second one
+	for i := 0; i < 2; i++ {
+		_, err := comp.GetCandles("SPY", "D", DateRange{})
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 1, down.calls)
+	assert.Equal(t, 2, backup.calls)
+	assert.Equal(t, 1, comp.Health()[0].Failures)
+	assert.Equal(t, 0, comp.Health()[1].Failures)
+}
@@ -0,0 +1,316 @@
+package marketdata
+
+// Normalized trade condition. Raw vendor codes are decoded with DecodeTradeCondition
+type TradeCondition string
+
+const (
+	CondRegular                    TradeCondition = "regular"
+	CondAcquisition                TradeCondition = "acquisition"
+	CondAveragePrice               TradeCondition = "average_price"
+	CondAutomaticExecution         TradeCondition = "automatic_execution"
+	CondBunched                    TradeCondition = "bunched"
+	CondBunchedSold                TradeCondition = "bunched_sold"
+	CondCAPElection                TradeCondition = "cap_election"
+	CondCash                       TradeCondition = "cash"
+	CondClosing                    TradeCondition = "closing"
+	CondCross                      TradeCondition = "cross"
+	CondDerivativelyPriced         TradeCondition = "derivatively_priced"
+	CondDistribution               TradeCondition = "distribution"
+	CondFormT                      TradeCondition = "form_t"
+	CondFormTOutOfSequence         TradeCondition = "form_t_out_of_sequence"
+	CondIntermarketSweep           TradeCondition = "intermarket_sweep"
+	CondOfficialClose              TradeCondition = "official_close"
+	CondOfficialOpen               TradeCondition = "official_open"
+	CondMarketCenterOpening        TradeCondition = "market_center_opening"
+	CondMarketCenterReopening      TradeCondition = "market_center_reopening"
+	CondMarketCenterClosing        TradeCondition = "market_center_closing"
+	CondNextDay                    TradeCondition = "next_day"
+	CondPriceVariation             TradeCondition = "price_variation"
+	CondPriorReferencePrice        TradeCondition = "prior_reference_price"
+	CondRule155                    TradeCondition = "rule_155"
+	CondRule127                    TradeCondition = "rule_127"
+	CondOpening                    TradeCondition = "opening"
+	CondOpened                     TradeCondition = "opened"
+	CondStoppedStock               TradeCondition = "stopped_stock"
+	CondReopening                  TradeCondition = "reopening"
+	CondSeller                     TradeCondition = "seller"
+	CondSoldLast                   TradeCondition = "sold_last"
+	CondSoldLastStoppedStock       TradeCondition = "sold_last_stopped_stock"
+	CondSoldOutOfSequence          TradeCondition = "sold_out_of_sequence"
+	CondSoldOutOfSequenceStopped   TradeCondition = "sold_out_of_sequence_stopped_stock"
+	CondSplit                      TradeCondition = "split"
+	CondStockOption                TradeCondition = "stock_option"
+	CondYellowFlag                 TradeCondition = "yellow_flag"
+	CondOddLot                     TradeCondition = "odd_lot"
+	CondCorrectedConsolidatedClose TradeCondition = "corrected_consolidated_close"
+	CondUnknown                    TradeCondition = "unknown"
+)
+
+// Normalized quote condition
+type QuoteCondition string
+
+const (
+	QuoteRegular           QuoteCondition = "regular"
+	QuoteTwoSidedOpen      QuoteCondition = "two_sided_open"
+	QuoteOneSidedOpen      QuoteCondition = "one_sided_open"
+	QuoteSlowAsk           QuoteCondition = "slow_ask"
+	QuoteSlowBid           QuoteCondition = "slow_bid"
+	QuoteSlowBidAsk        QuoteCondition = "slow_bid_ask"
+	QuoteSlowLRPBid        QuoteCondition = "slow_lrp_bid"
+	QuoteSlowLRPAsk        QuoteCondition = "slow_lrp_ask"
+	QuoteSlowNYSELRP       QuoteCondition = "slow_nyse_lrp"
+	QuoteSlowList          QuoteCondition = "slow_list"
+	QuoteManualAsk         QuoteCondition = "manual_ask"
+	QuoteManualBid         QuoteCondition = "manual_bid"
+	QuoteManualBidAsk      QuoteCondition = "manual_bid_ask"
+	QuoteOpening           QuoteCondition = "opening"
+	QuoteClosing           QuoteCondition = "closing"
+	QuoteClosed            QuoteCondition = "closed"
+	QuoteResume            QuoteCondition = "resume"
+	QuoteFastTrading       QuoteCondition = "fast_trading"
+	QuoteTradingRange      QuoteCondition = "trading_range_indication"
+	QuoteMarketMakerClose  QuoteCondition = "market_maker_quotes_closed"
+	QuoteNonFirm           QuoteCondition = "non_firm"
+	QuoteNewsDissemination QuoteCondition = "news_dissemination"
+	QuoteOrderInflux       QuoteCondition = "order_influx"
+	QuoteOrderImbalance    QuoteCondition = "order_imbalance"
+	QuoteNewsPending       QuoteCondition = "news_pending"
+	QuoteNoOpenNoResume    QuoteCondition = "no_open_no_resume"
+	QuoteUnknown           QuoteCondition = "unknown"
+)
+
+// Market center. Codes are SIP participant ids used by ActiveTick
+type Exchange string
+
+const (
+	ExchNYSEAmerican Exchange = "NYSE American"
+	ExchNasdaqBX     Exchange = "Nasdaq BX"
+	ExchNYSENational Exchange = "NYSE National"
+	ExchFINRAADF     Exchange = "FINRA ADF"
+	ExchConsolidated Exchange = "Consolidated"
+	ExchMIAXPearl    Exchange = "MIAX Pearl"
+	ExchISE          Exchange = "ISE"
+	ExchCboeEDGA     Exchange = "Cboe EDGA"
+	ExchCboeEDGX     Exchange = "Cboe EDGX"
+	ExchLTSE         Exchange = "LTSE"
+	ExchNYSEChicago  Exchange = "NYSE Chicago"
+	ExchNYSE         Exchange = "NYSE"
+	ExchNYSEArca     Exchange = "NYSE Arca"
+	ExchNasdaq       Exchange = "Nasdaq"
+	ExchNasdaqTRF    Exchange = "Nasdaq TRF"
+	ExchMEMX         Exchange = "MEMX"
+	ExchIEX          Exchange = "IEX"
+	ExchCboe         Exchange = "Cboe"
+	ExchNasdaqPSX    Exchange = "Nasdaq PSX"
+	ExchCboeBYX      Exchange = "Cboe BYX"
+	ExchCboeBZX      Exchange = "Cboe BZX"
+	ExchNone         Exchange = ""
+	ExchUnknown      Exchange = "unknown"
+)
+
+// Which consolidated values trade updates according To consolidated tape rules
+type TapeRule struct {
+	UpdatesLast    bool
+	UpdatesHighLow bool
+	UpdatesVolume  bool
+}
+
+var activeTickTradeConditions = map[string]TradeCondition{
+	"0":  CondRegular,
+	"1":  CondAcquisition,
+	"2":  CondAveragePrice,
+	"3":  CondAutomaticExecution,
+	"4":  CondBunched,
+	"5":  CondBunchedSold,
+	"6":  CondCAPElection,
+	"7":  CondCash,
+	"8":  CondClosing,
+	"9":  CondCross,
+	"10": CondDerivativelyPriced,
+	"11": CondDistribution,
+	"12": CondFormT,
+	"13": CondFormTOutOfSequence,
+	"14": CondIntermarketSweep,
+	"15": CondOfficialClose,
+	"16": CondOfficialOpen,
+	"17": CondMarketCenterOpening,
+	"18": CondMarketCenterReopening,
+	"19": CondMarketCenterClosing,
+	"20": CondNextDay,
+	"21": CondPriceVariation,
+	"22": CondPriorReferencePrice,
+	"23": CondRule155,
+	"24": CondRule127,
+	"25": CondOpening,
+	"26": CondOpened,
+	"27": CondStoppedStock,
+	"28": CondReopening,
+	"29": CondSeller,
+	"30": CondSoldLast,
+	"31": CondSoldLastStoppedStock,
+	"32": CondSoldOutOfSequence,
+	"33": CondSoldOutOfSequenceStopped,
+	"34": CondSplit,
+	"35": CondStockOption,
+	"36": CondYellowFlag,
+	"37": CondOddLot,
+	"38": CondCorrectedConsolidatedClose,
+}
+
+var activeTickQuoteConditions = map[string]QuoteCondition{
+	"0":  QuoteRegular,
+	"1":  QuoteTwoSidedOpen,
+	"2":  QuoteOneSidedOpen,
+	"3":  QuoteSlowAsk,
+	"4":  QuoteSlowBid,
+	"5":  QuoteSlowBidAsk,
+	"6":  QuoteSlowLRPBid,
+	"7":  QuoteSlowLRPAsk,
+	"8":  QuoteSlowNYSELRP,
+	"9":  QuoteSlowList,
+	"10": QuoteManualAsk,
+	"11": QuoteManualBid,
+	"12": QuoteManualBidAsk,
+	"13": QuoteOpening,
+	"14": QuoteClosing,
+	"15": QuoteClosed,
+	"16": QuoteResume,
+	"17": QuoteFastTrading,
+	"18": QuoteTradingRange,
+	"19": QuoteMarketMakerClose,
+	"20": QuoteNonFirm,
+	"21": QuoteNewsDissemination,
+	"22": QuoteOrderInflux,
+	"23": QuoteOrderImbalance,
+	"27": QuoteNewsPending,
+	"32": QuoteNoOpenNoResume,
+}
+
+var activeTickExchanges = map[string]Exchange{
+	"A": ExchNYSEAmerican,
+	"B": ExchNasdaqBX,
+	"C": ExchNYSENational,
+	"D": ExchFINRAADF,
+	"E": ExchConsolidated,
+	"H": ExchMIAXPearl,
+	"I": ExchISE,
+	"J": ExchCboeEDGA,
+	"K": ExchCboeEDGX,
+	"L": ExchLTSE,
+	"M": ExchNYSEChicago,
+	"N": ExchNYSE,
+	"P": ExchNYSEArca,
+	"Q": ExchNasdaq,
+	"S": ExchConsolidated,
+	"T": ExchNasdaqTRF,
+	"U": ExchMEMX,
+	"V": ExchIEX,
+	"W": ExchCboe,
+	"X": ExchNasdaqPSX,
+	"Y": ExchCboeBYX,
+	"Z": ExchCboeBZX,
+	// Quote side without exchange
+	"0": ExchNone,
+	" ": ExchNone,
+	"":  ExchNone,
+}
+
+var regularTape = TapeRule{true, true, true}
+
+// Consolidated tape rules of conditions which differ From regular trade
+var tradeConditionTapeRules = map[TradeCondition]TapeRule{
+	CondAveragePrice:               {false, false, true},
+	CondBunchedSold:                {false, true, true},
+	CondCash:                       {false, false, true},
+	CondDerivativelyPriced:         {false, true, true},
+	CondFormT:                      {false, false, true},
+	CondFormTOutOfSequence:         {false, false, true},
+	CondOfficialClose:              {false, false, false},
+	CondOfficialOpen:               {false, false, false},
+	CondNextDay:                    {false, false, true},
+	CondPriceVariation:             {false, false, true},
+	CondPriorReferencePrice:        {false, true, true},
+	CondOpened:                     {false, true, true},
+	CondSeller:                     {false, false, true},
+	CondSoldOutOfSequence:          {false, true, true},
+	CondSoldOutOfSequenceStopped:   {false, true, true},
+	CondOddLot:                     {false, false, true},
+	CondCorrectedConsolidatedClose: {true, true, false},
+}
+
+func DecodeTradeCondition(code string) TradeCondition {
+	if c, ok := activeTickTradeConditions[code]; ok {
+		return c
+	}
+	return CondUnknown
+}
+
+func DecodeQuoteCondition(code string) QuoteCondition {
+	if c, ok := activeTickQuoteConditions[code]; ok {
+		return c
+	}
+	return QuoteUnknown
+}
+
+func DecodeExchange(code string) Exchange {
+	if e, ok := activeTickExchanges[code]; ok {
+		return e
+	}
+	return ExchUnknown
+}
+
+func (c TradeCondition) TapeRule() TapeRule {
+	if r, ok := tradeConditionTapeRules[c]; ok {
+		return r
+	}
+	return regularTape
+}
+
+func (c TradeCondition) isOpening() bool {
+	return c == CondOpening || c == CondMarketCenterOpening || c == CondOfficialOpen
+}
+
+func (c TradeCondition) isClosing() bool {
+	return c == CondClosing || c == CondMarketCenterClosing || c == CondOfficialClose ||
+		c == CondCorrectedConsolidatedClose
+}
+
+// Decoded conditions of trade. Regular placeholders are skipped, so regular trade has no conditions
+func (t *Tick) TradeConditions() []TradeCondition {
+	var conditions []TradeCondition
+	for _, code := range []string{t.Cond1, t.Cond2, t.Cond3, t.Cond4} {
+		if code == "" {
+			continue
+		}
+		c := DecodeTradeCondition(code)
+		if c == CondRegular {
+			continue
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions
+}
+
+func (t *Tick) QuoteCondition() QuoteCondition {
+	return DecodeQuoteCondition(t.CondQuote)
+}
+
+// Tape rule of trade. Every condition can only restrict updates
+func (t *Tick) TapeRule() TapeRule {
+	rule := regularTape
+	for _, c := range t.TradeConditions() {
+		r := c.TapeRule()
+		rule.UpdatesLast = rule.UpdatesLast && r.UpdatesLast
+		rule.UpdatesHighLow = rule.UpdatesHighLow && r.UpdatesHighLow
+		rule.UpdatesVolume = rule.UpdatesVolume && r.UpdatesVolume
+	}
+	return rule
+}
+
+// Sets IsOpening and IsClosing From trade conditions
+func (t *Tick) setOpeningClosing() {
+	for _, c := range t.TradeConditions() {
+		t.IsOpening = t.IsOpening || c.isOpening()
+		t.IsClosing = t.IsClosing || c.isClosing()
+	}
+}
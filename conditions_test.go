@@ -0,0 +1,46 @@
+package marketdata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeConditions(t *testing.T) {
+	assert.Equal(t, CondOddLot, DecodeTradeCondition("37"))
+	assert.Equal(t, CondIntermarketSweep, DecodeTradeCondition("14"))
+	assert.Equal(t, CondUnknown, DecodeTradeCondition("99"))
+	assert.Equal(t, QuoteClosed, DecodeQuoteCondition("15"))
+	assert.Equal(t, ExchNYSEArca, DecodeExchange("P"))
+	assert.Equal(t, ExchNasdaq, DecodeExchange("Q"))
+	assert.Equal(t, ExchNone, DecodeExchange(" "))
+	assert.Equal(t, ExchUnknown, DecodeExchange("?"))
+}
+
+func TestTick_TapeRule(t *testing.T) {
+	raw := "T,20181101093000551,79.260000,100,Q,0,0,14,0\r\n" +
+		"T,20181101093000552,79.260000,3,D,37,0,14,0\r\n" +
+		"T,20181101093000553,79.310000,100,Q,9,0,25,0\r\n" +
+		"T,20181101160000100,79.020000,0,Q,15,0,0,0\r\n" +
+		"T,20181101160000200,79.020000,500,P,0,0,8,0\r\n"
+
+	ticks, err := parseToTQ(raw, noDecimals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, TapeRule{true, true, true}, ticks[0].TapeRule())
+	assert.Equal(t, []TradeCondition{CondIntermarketSweep}, ticks[0].TradeConditions())
+
+	// Odd lot ISO updates volume only
+	assert.Equal(t, TapeRule{false, false, true}, ticks[1].TapeRule())
+
+	assert.True(t, ticks[2].IsOpening)
+	assert.False(t, ticks[2].IsClosing)
+
+	// Official close doesn't update anything, but it's closing print
+	assert.Equal(t, TapeRule{false, false, false}, ticks[3].TapeRule())
+	assert.True(t, ticks[3].IsClosing)
+	assert.True(t, ticks[4].IsClosing)
+	assert.False(t, ticks[4].IsOpening)
+}
@@ -0,0 +1,377 @@
+package marketdata
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	corporateActionsFileName = "corporate_actions.json"
+	corporateActionsLayout   = "2006-01-02"
+)
+
+type CorporateActionType string
+
+const (
+	Split        CorporateActionType = "split"
+	CashDividend CorporateActionType = "dividend"
+	SymbolChange CorporateActionType = "symbol_change"
+)
+
+type ErrParsingCorporateAction struct {
+	raw    string
+	reason string
+}
+
+func (e *ErrParsingCorporateAction) Error() string {
+	return fmt.Sprintf("Can't parse corporate action %q: %v", e.raw, e.reason)
+}
+
+// Ratio is count of new shares for one old share: 2 for 2:1 split, 0.1 for 1:10 reverse split.
+// Amount is cash dividend per share. NewSymbol is filled for symbol changes.
+type CorporateAction struct {
+	Symbol    string
+	Type      CorporateActionType
+	ExDate    time.Time
+	Ratio     float64
+	Amount    float64
+	NewSymbol string
+}
+
+// Multipliers for prices and sizes of everything before ExDate
+type AdjustmentFactor struct {
+	ExDate time.Time
+	Price  float64
+	Size   float64
+}
+
+// Corporate actions by symbol. Persisted To single json file.
+type CorporateActionsStore struct {
+	Path string
+
+	actions map[string][]*CorporateAction
+	mu      sync.RWMutex
+}
+
+func NewCorporateActionsStore(pth string) *CorporateActionsStore {
+	return &CorporateActionsStore{
+		Path:    pth,
+		actions: make(map[string][]*CorporateAction),
+	}
+}
+
+// Loads store From file. Missing file gives empty store
+func LoadCorporateActions(pth string) (*CorporateActionsStore, error) {
+	s := NewCorporateActionsStore(pth)
+	if !fileExists(pth) {
+		return s, nil
+	}
+
+	byteValue, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []*CorporateAction
+	err = json.Unmarshal(byteValue, &actions)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range actions {
+		s.Add(*a)
+	}
+
+	return s, nil
+}
+
+func (s *CorporateActionsStore) Save() error {
+	err := createDirIfNotExists(filepath.Dir(s.Path))
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	var symbols []string
+	for symbol := range s.actions {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var actions []*CorporateAction
+	for _, symbol := range symbols {
+		actions = append(actions, s.actions[symbol]...)
+	}
+	s.mu.RUnlock()
+
+	json_, err := json.MarshalIndent(actions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.Path, json_, 0644)
+}
+
+// Adds action. The same action added twice is stored once
+func (s *CorporateActionsStore) Add(a CorporateAction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.actions[a.Symbol] {
+		if *stored == a {
+			return
+		}
+	}
+
+	s.actions[a.Symbol] = append(s.actions[a.Symbol], &a)
+	sort.SliceStable(s.actions[a.Symbol], func(i, j int) bool {
+		return s.actions[a.Symbol][i].ExDate.Before(s.actions[a.Symbol][j].ExDate)
+	})
+}
+
+// Returns symbol actions sorted by ex date
+func (s *CorporateActionsStore) Get(symbol string) []*CorporateAction {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	actions := make([]*CorporateAction, len(s.actions[symbol]))
+	copy(actions, s.actions[symbol])
+	return actions
+}
+
+// Imports actions From CSV with columns: Symbol,Type,ExDate,Value[,NewSymbol]. Header row is optional.
+// Type is split, dividend or symbol_change. ExDate is in 2006-01-02 format. Value is split ratio (2, 3:2 or 1/10)
+// or dividend amount. Value is ignored for symbol changes. Example:
+//
+//	AAPL,split,2020-08-31,4:1
+//	AAPL,dividend,2020-08-07,0.82
+//	FB,symbol_change,2022-06-09,,META
+func (s *CorporateActionsStore) ImportCSV(r io.Reader) (int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	imported := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return imported, err
+		}
+
+		if len(record) == 0 || strings.EqualFold(record[0], "symbol") {
+			continue
+		}
+
+		action, err := parseCorporateAction(record)
+		if err != nil {
+			return imported, err
+		}
+
+		s.Add(*action)
+		imported++
+	}
+
+	return imported, nil
+}
+
+func parseCorporateAction(record []string) (*CorporateAction, error) {
+	raw := strings.Join(record, ",")
+	if len(record) < 3 {
+		return nil, &ErrParsingCorporateAction{raw, "not enough columns"}
+	}
+
+	exDate, err := time.Parse(corporateActionsLayout, record[2])
+	if err != nil {
+		return nil, &ErrParsingCorporateAction{raw, "wrong ex date"}
+	}
+
+	action := CorporateAction{
+		Symbol: strings.TrimSpace(record[0]),
+		Type:   CorporateActionType(strings.ToLower(strings.TrimSpace(record[1]))),
+		ExDate: exDate,
+	}
+
+	value := ""
+	if len(record) > 3 {
+		value = strings.TrimSpace(record[3])
+	}
+
+	switch action.Type {
+	case Split:
+		action.Ratio, err = parseSplitRatio(value)
+		if err != nil {
+			return nil, &ErrParsingCorporateAction{raw, "wrong split ratio"}
+		}
+	case CashDividend:
+		action.Amount, err = strconv.ParseFloat(value, 64)
+		if err != nil || action.Amount < 0 {
+			return nil, &ErrParsingCorporateAction{raw, "wrong dividend amount"}
+		}
+	case SymbolChange:
+		if len(record) < 5 || strings.TrimSpace(record[4]) == "" {
+			return nil, &ErrParsingCorporateAction{raw, "new symbol not specified"}
+		}
+		action.NewSymbol = strings.TrimSpace(record[4])
+	default:
+		return nil, &ErrParsingCorporateAction{raw, "unknown action type"}
+	}
+
+	return &action, nil
+}
+
+func parseSplitRatio(s string) (float64, error) {
+	for _, sep := range []string{":", "/"} {
+		parts := strings.Split(s, sep)
+		if len(parts) != 2 {
+			continue
+		}
+		newShares, err := strconv.ParseFloat(parts[0], 64)
+		if err != nil {
+			return 0, err
+		}
+		oldShares, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return 0, err
+		}
+		if newShares <= 0 || oldShares <= 0 {
+			return 0, fmt.Errorf("ratio should be positive: %v", s)
+		}
+		return newShares / oldShares, nil
+	}
+
+	ratio, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if ratio <= 0 {
+		return 0, fmt.Errorf("ratio should be positive: %v", s)
+	}
+	return ratio, nil
+}
+
+// Calculates adjustment factors for actions. Dividend factor is 1 - Amount / Close where Close is the last close
+// before ex date, so candles covering that date are required. Dividends without previous close are skipped.
+// Symbol changes don't change prices.
+func AdjustmentFactors(actions []*CorporateAction, candles CandleArray) []AdjustmentFactor {
+	sorted := make(CandleArray, len(candles))
+	copy(sorted, candles)
+	sorted.Sort()
+
+	var factors []AdjustmentFactor
+	for _, a := range actions {
+		switch a.Type {
+		case Split:
+			if a.Ratio <= 0 {
+				continue
+			}
+			factors = append(factors, AdjustmentFactor{a.ExDate, 1 / a.Ratio, a.Ratio})
+		case CashDividend:
+			prevClose := closeBefore(sorted, a.ExDate)
+			if prevClose <= 0 || a.Amount >= prevClose {
+				continue
+			}
+			factors = append(factors, AdjustmentFactor{a.ExDate, 1 - a.Amount/prevClose, 1})
+		}
+	}
+
+	return factors
+}
+
+func closeBefore(sorted CandleArray, d time.Time) float64 {
+	exDay := setTimeToSOD(d)
+	prevClose := 0.0
+	for _, c := range sorted {
+		if !c.Datetime.Before(exDay) {
+			break
+		}
+		prevClose = c.Close
+	}
+	return prevClose
+}
+
+// Cumulative factor for moment t. Everything before ex date is adjusted
+func cumulativeFactor(factors []AdjustmentFactor, t time.Time) (float64, float64) {
+	price, size := 1.0, 1.0
+	for _, f := range factors {
+		if t.Before(setTimeToSOD(f.ExDate)) {
+			price *= f.Price
+			size *= f.Size
+		}
+	}
+	return price, size
+}
+
+// Returns back-adjusted copy of candles. The latest prices stay as is. Candles should cover dividend ex dates,
+// otherwise dividends are ignored.
+func AdjustCandles(candles CandleArray, actions []*CorporateAction) CandleArray {
+	factors := AdjustmentFactors(actions, candles)
+
+	adjusted := make(CandleArray, 0, len(candles))
+	for _, c := range candles {
+		price, size := cumulativeFactor(factors, c.Datetime)
+
+		candle := *c
+		candle.Open = c.Open * price
+		candle.High = c.High * price
+		candle.Low = c.Low * price
+		candle.Close = c.Close * price
+		candle.AdjClose = c.Close * price
+		candle.Volume = int64(math.Round(float64(c.Volume) * size))
+		candle.syncExact()
+		adjusted = append(adjusted, &candle)
+	}
+
+	return adjusted
+}
+
+// Returns back-adjusted copy of ticks. Factors can be calculated by AdjustmentFactors From daily candles.
+// Negative prices and sizes (missing values) are kept as is.
+func AdjustTicks(ticks TickArray, factors []AdjustmentFactor) TickArray {
+	adjusted := make(TickArray, 0, len(ticks))
+	for _, t := range ticks {
+		price, size := cumulativeFactor(factors, t.Datetime)
+
+		tick := *t
+		tick.LastPrice = adjustPrice(t.LastPrice, price)
+		tick.BidPrice = adjustPrice(t.BidPrice, price)
+		tick.AskPrice = adjustPrice(t.AskPrice, price)
+		tick.LastSize = adjustSize(t.LastSize, size)
+		tick.BidSize = adjustSize(t.BidSize, size)
+		tick.AskSize = adjustSize(t.AskSize, size)
+		tick.syncExact()
+		adjusted = append(adjusted, &tick)
+	}
+
+	return adjusted
+}
+
+func adjustPrice(p float64, factor float64) float64 {
+	if p <= 0 {
+		return p
+	}
+	return p * factor
+}
+
+func adjustSize(s int64, factor float64) int64 {
+	if s <= 0 {
+		return s
+	}
+	return int64(math.Round(float64(s) * factor))
+}
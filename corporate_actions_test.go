@@ -0,0 +1,87 @@
+package marketdata
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorporateActionsStore_ImportCSV(t *testing.T) {
+	pth := "./test_data/corporate_actions_test/corporate_actions.json"
+	defer os.RemoveAll("./test_data/corporate_actions_test")
+
+	csv := "Symbol,Type,ExDate,Value,NewSymbol\n" +
+		"AAPL,split,2020-08-31,4:1\n" +
+		"AAPL,dividend,2020-08-07,0.82\n" +
+		"AAPL,dividend,2020-08-07,0.82\n" +
+		"FB,symbol_change,2022-06-09,,META\n"
+
+	store := NewCorporateActionsStore(pth)
+	imported, err := store.ImportCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 4, imported)
+
+	err = store.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadCorporateActions(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actions := loaded.Get("AAPL")
+	assert.Equal(t, 2, len(actions))
+	assert.Equal(t, CashDividend, actions[0].Type)
+	assert.Equal(t, 4.0, actions[1].Ratio)
+	assert.Equal(t, "META", loaded.Get("FB")[0].NewSymbol)
+
+	_, err = store.ImportCSV(strings.NewReader("AAPL,merger,2020-08-31,1\n"))
+	assert.IsType(t, &ErrParsingCorporateAction{}, err)
+}
+
+func TestAdjustCandles(t *testing.T) {
+	candles := CandleArray{
+		{Symbol: "TEST", Open: 100, High: 110, Low: 90, Close: 100, AdjClose: 100, Volume: 1000, Datetime: timeOnTheFly(2018, 1, 1)},
+		{Symbol: "TEST", Open: 100, High: 100, Low: 100, Close: 100, AdjClose: 100, Volume: 1000, Datetime: timeOnTheFly(2018, 1, 2)},
+		{Symbol: "TEST", Open: 49, High: 50, Low: 48, Close: 50, AdjClose: 50, Volume: 2000, Datetime: timeOnTheFly(2018, 1, 3)},
+	}
+
+	actions := []*CorporateAction{
+		{Symbol: "TEST", Type: CashDividend, ExDate: timeOnTheFly(2018, 1, 2), Amount: 10},
+		{Symbol: "TEST", Type: Split, ExDate: timeOnTheFly(2018, 1, 3), Ratio: 2},
+	}
+
+	adjusted := AdjustCandles(candles, actions)
+
+	// Split 2:1 and 10% dividend
+	assert.InDelta(t, 45, adjusted[0].Close, 1e-9)
+	assert.InDelta(t, 49.5, adjusted[0].High, 1e-9)
+	assert.InDelta(t, 45, adjusted[0].AdjClose, 1e-9)
+	assert.Equal(t, int64(2000), adjusted[0].Volume)
+
+	// Only split
+	assert.InDelta(t, 50, adjusted[1].Close, 1e-9)
+	assert.Equal(t, int64(2000), adjusted[1].Volume)
+
+	// Latest prices are not changed
+	assert.Equal(t, *candles[2], *adjusted[2])
+
+	// Raw candles are not modified
+	assert.Equal(t, 100.0, candles[0].Close)
+
+	ticks := TickArray{
+		{LastPrice: 100, LastSize: 100, BidPrice: -1, AskPrice: -1, BidSize: -1, AskSize: -1,
+			Datetime: time.Date(2018, 1, 2, 10, 0, 0, 0, time.UTC)},
+	}
+	adjustedTicks := AdjustTicks(ticks, AdjustmentFactors(actions, candles))
+	assert.InDelta(t, 50, adjustedTicks[0].LastPrice, 1e-9)
+	assert.Equal(t, int64(200), adjustedTicks[0].LastSize)
+	assert.Equal(t, -1.0, adjustedTicks[0].BidPrice)
+}
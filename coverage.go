@@ -0,0 +1,74 @@
+package marketdata
+
+import (
+	"path"
+	"sort"
+	"time"
+)
+
+// Stored days of one dataset: candles/day, ticks/quotes, ticks/trades or ticks/quotes_trades
+type DatasetCoverage struct {
+	Days   int
+	Ranges []DateRange // Continuous ranges of stored days. Non trading days don't break range
+}
+
+type SymbolCoverage struct {
+	Symbol   string
+	Datasets map[string]DatasetCoverage
+}
+
+// Coverage of symbol read From meta files. Datasets without meta are not included
+func (p *JsonStorage) Coverage(symbol string) (*SymbolCoverage, error) {
+	coverage := SymbolCoverage{Symbol: symbol, Datasets: make(map[string]DatasetCoverage)}
+
+	metas := map[string]string{
+		"candles/day": path.Join(p.Path, "candles", "day", metaFolder, symbolStoragePath(symbol)+".json"),
+	}
+	for _, kind := range [][2]bool{{true, false}, {false, true}, {true, true}} {
+		metas["ticks/"+p.generateTicksFolderName(kind[0], kind[1])] = p.symbolTicksMetaPath(symbol, kind[0], kind[1])
+	}
+
+	for dataset, metaPath := range metas {
+		if !fileExists(metaPath) {
+			continue
+		}
+		meta := JsonSymbolMeta{}
+		err := meta.Load(metaPath)
+		if err != nil {
+			return nil, err
+		}
+		coverage.Datasets[dataset] = DatasetCoverage{
+			Days:   len(meta.ListedDates),
+			Ranges: listedRanges(meta.ListedDates, p.marketMode()),
+		}
+	}
+
+	return &coverage, nil
+}
+
+// Joins listed dates into ranges. Dates are joined if there are only non trading days between them
+func listedRanges(dates []time.Time, mode MarketMode) []DateRange {
+	sorted := append([]time.Time(nil), dates...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Before(sorted[j])
+	})
+
+	var ranges []DateRange
+	for _, d := range sorted {
+		if len(ranges) > 0 {
+			last := &ranges[len(ranges)-1]
+			next := last.To.AddDate(0, 0, 1)
+			for !mode.isTradingDay(next) && next.Before(d) {
+				next = next.AddDate(0, 0, 1)
+			}
+			if !d.After(next) {
+				if d.After(last.To) {
+					last.To = d
+				}
+				continue
+			}
+		}
+		ranges = append(ranges, DateRange{d, d})
+	}
+	return ranges
+}
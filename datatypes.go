@@ -27,6 +27,7 @@ type Tick struct {
 	LastSize  int64
 	LastExch  string
 	Datetime  time.Time
+	Seq       int64 // Arrival order of tick in provider response. Orders ticks with the same Datetime
 
 	BidExch string
 	AskExch string
@@ -41,6 +42,10 @@ type Tick struct {
 	Cond2     string
 	Cond3     string
 	Cond4     string
+
+	Source string // Name of provider which served this tick. Filled by CompositeProvider
+
+	Exact *TickDecimals `json:",omitempty"` // Exact prices. Filled only if provider has decimal prices enabled
 }
 
 func (t *Tick) HasQuote() bool {
@@ -78,11 +83,34 @@ func (t *Tick) IsValid() bool{
 	return true
 }
 
+// Returns exact prices if they are parsed or prices converted From floats with given scale
+func (t *Tick) Decimals(scale uint8) TickDecimals {
+	if t.Exact != nil {
+		return *t.Exact
+	}
+	return TickDecimals{
+		DecimalFromFloat(t.LastPrice, scale),
+		DecimalFromFloat(t.BidPrice, scale),
+		DecimalFromFloat(t.AskPrice, scale),
+	}
+}
+
+// Recalculates exact prices From floats after prices modification. Does nothing if tick has no exact prices
+func (t *Tick) syncExact() {
+	if t.Exact == nil {
+		return
+	}
+	scale := t.Exact.LastPrice.Scale
+	t.Exact = nil
+	d := t.Decimals(scale)
+	t.Exact = &d
+}
+
 func (t *Tick) String() string {
 	if t == nil {
 		return ""
 	}
-	str := fmt.Sprintf("%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v", t.Datetime.Unix(), t.Symbol, t.LastPrice,
+	str := fmt.Sprintf("%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v", t.Datetime.UnixNano(), t.Symbol, t.LastPrice,
 		t.LastSize, t.LastExch, t.BidPrice, t.BidSize, t.BidExch,
 		t.AskPrice, t.AskSize, t.AskExch, t.CondQuote, t.Cond1, t.Cond2, t.Cond3, t.Cond4)
 
@@ -99,6 +127,34 @@ type Candle struct {
 	Volume       int64
 	OpenInterest int64
 	Datetime     time.Time
+	Source       string // Name of provider which served this candle. Filled by CompositeProvider
+
+	Exact *CandleDecimals `json:",omitempty"` // Exact prices. Filled only if provider has decimal prices enabled
+}
+
+// Returns exact prices if they are parsed or prices converted From floats with given scale
+func (c *Candle) Decimals(scale uint8) CandleDecimals {
+	if c.Exact != nil {
+		return *c.Exact
+	}
+	return CandleDecimals{
+		DecimalFromFloat(c.Open, scale),
+		DecimalFromFloat(c.High, scale),
+		DecimalFromFloat(c.Low, scale),
+		DecimalFromFloat(c.Close, scale),
+		DecimalFromFloat(c.AdjClose, scale),
+	}
+}
+
+// Recalculates exact prices From floats after prices modification. Does nothing if candle has no exact prices
+func (c *Candle) syncExact() {
+	if c.Exact == nil {
+		return
+	}
+	scale := c.Exact.Close.Scale
+	c.Exact = nil
+	d := c.Decimals(scale)
+	c.Exact = &d
 }
 
 func (c *Candle) String() string {
@@ -114,15 +170,23 @@ type CandleArray []*Candle
 
 func (t CandleArray) Sort() {
 	sort.SliceStable(t, func(i, j int) bool {
-		return t[i].Datetime.Unix() < t[j].Datetime.Unix()
+		return t[i].Datetime.Before(t[j].Datetime)
 	})
 }
 
 type TickArray []*Tick
 
+// Sorts ticks by time with nanoseconds. Ticks with the same time are ordered by arrival sequence
 func (t TickArray) Sort() {
 	sort.SliceStable(t, func(i, j int) bool {
-		return t[i].Datetime.Unix() < t[j].Datetime.Unix()
+		return t[i].before(t[j])
 	})
 
 }
+
+func (t *Tick) before(other *Tick) bool {
+	if !t.Datetime.Equal(other.Datetime) {
+		return t.Datetime.Before(other.Datetime)
+	}
+	return t.Seq < other.Seq
+}
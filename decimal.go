@@ -0,0 +1,223 @@
+package marketdata
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxDecimalScale = 9
+	// Parsers don't fill decimal prices with this scale
+	noDecimals = -1
+)
+
+var pow10 = [maxDecimalScale + 1]int64{1, 10, 100, 1000, 10000, 100000, 1000000, 10000000, 100000000, 1000000000}
+
+type ErrParsingDecimal struct {
+	raw string
+}
+
+func (e *ErrParsingDecimal) Error() string {
+	return fmt.Sprintf("Can't parse decimal: %q", e.raw)
+}
+
+// Exact decimal price. Value is Units / 10^Scale, so 79.26 with scale 2 is stored as 7926
+type Decimal struct {
+	Units int64
+	Scale uint8
+}
+
+// Parses decimal string exactly. Digits after scale are rounded half away From zero
+func ParseDecimal(s string, scale uint8) (Decimal, error) {
+	if scale > maxDecimalScale {
+		return Decimal{}, &ErrParsingDecimal{s}
+	}
+
+	str := strings.TrimSpace(s)
+	negative := false
+	if strings.HasPrefix(str, "-") || strings.HasPrefix(str, "+") {
+		negative = str[0] == '-'
+		str = str[1:]
+	}
+
+	intPart, fracPart := str, ""
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		intPart, fracPart = str[:i], str[i+1:]
+	}
+	if intPart == "" && fracPart == "" {
+		return Decimal{}, &ErrParsingDecimal{s}
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+
+	units, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return Decimal{}, &ErrParsingDecimal{s}
+	}
+	if units > math.MaxInt64/pow10[scale] {
+		return Decimal{}, &ErrParsingDecimal{s}
+	}
+	units *= pow10[scale]
+
+	for i := 0; i < len(fracPart); i++ {
+		digit := fracPart[i]
+		if digit < '0' || digit > '9' {
+			return Decimal{}, &ErrParsingDecimal{s}
+		}
+		if i < int(scale) {
+			units += int64(digit-'0') * pow10[int(scale)-i-1]
+			continue
+		}
+		if i == int(scale) && digit >= '5' {
+			units++
+		}
+	}
+
+	if negative {
+		units = -units
+	}
+
+	return Decimal{units, scale}, nil
+}
+
+// Converts float To decimal with given scale. Float is rounded To the nearest decimal
+func DecimalFromFloat(f float64, scale uint8) Decimal {
+	if scale > maxDecimalScale {
+		scale = maxDecimalScale
+	}
+	return Decimal{int64(math.Round(f * float64(pow10[scale]))), scale}
+}
+
+// Number of decimal places needed To represent tick size exactly: 0.01 -> 2, 0.25 -> 2, 1 -> 0
+func scaleOfTickSize(tickSize float64) uint8 {
+	for scale := uint8(0); scale < maxDecimalScale; scale++ {
+		v := tickSize * float64(pow10[scale])
+		if math.Abs(v-math.Round(v)) < 1e-9 {
+			return scale
+		}
+	}
+	return maxDecimalScale
+}
+
+func (d Decimal) Float64() float64 {
+	return float64(d.Units) / float64(pow10[d.Scale])
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Units == 0
+}
+
+// Returns decimal with new scale. Extra digits are rounded half away From zero
+func (d Decimal) Rescale(scale uint8) Decimal {
+	if scale == d.Scale {
+		return d
+	}
+	if scale > d.Scale {
+		return Decimal{d.Units * pow10[scale-d.Scale], scale}
+	}
+
+	div := pow10[d.Scale-scale]
+	units := d.Units / div
+	rem := d.Units % div
+	if rem*2 >= div {
+		units++
+	} else if rem*2 <= -div {
+		units--
+	}
+	return Decimal{units, scale}
+}
+
+func commonScale(a Decimal, b Decimal) (Decimal, Decimal) {
+	if a.Scale > b.Scale {
+		return a, b.Rescale(a.Scale)
+	}
+	return a.Rescale(b.Scale), b
+}
+
+func (d Decimal) Add(other Decimal) Decimal {
+	a, b := commonScale(d, other)
+	return Decimal{a.Units + b.Units, a.Scale}
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	a, b := commonScale(d, other)
+	return Decimal{a.Units - b.Units, a.Scale}
+}
+
+// Multiplies price by quantity. Scale is kept
+func (d Decimal) MulInt(q int64) Decimal {
+	return Decimal{d.Units * q, d.Scale}
+}
+
+// Returns -1, 0 or 1
+func (d Decimal) Cmp(other Decimal) int {
+	a, b := commonScale(d, other)
+	switch {
+	case a.Units < b.Units:
+		return -1
+	case a.Units > b.Units:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (d Decimal) String() string {
+	units := d.Units
+	sign := ""
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+
+	if d.Scale == 0 {
+		return sign + strconv.FormatInt(units, 10)
+	}
+
+	div := pow10[d.Scale]
+	return fmt.Sprintf("%v%d.%0*d", sign, units/div, int(d.Scale), units%div)
+}
+
+// Decimal is written To json as exact number with trailing zeros: 79.260000
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Scale is taken From number of digits after point
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+
+	scale := 0
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		scale = len(s) - i - 1
+	}
+	if scale > maxDecimalScale {
+		scale = maxDecimalScale
+	}
+
+	parsed, err := ParseDecimal(s, uint8(scale))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Exact prices of tick. Filled by parsers only if decimal prices are enabled
+type TickDecimals struct {
+	LastPrice Decimal
+	BidPrice  Decimal
+	AskPrice  Decimal
+}
+
+// Exact prices of candle. Filled by parsers only if decimal prices are enabled
+type CandleDecimals struct {
+	Open     Decimal
+	High     Decimal
+	Low      Decimal
+	Close    Decimal
+	AdjClose Decimal
+}
@@ -0,0 +1,90 @@
+package marketdata
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDecimal(t *testing.T) {
+	d, err := ParseDecimal("79.260000", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, Decimal{7926, 2}, d)
+	assert.Equal(t, "79.26", d.String())
+	assert.Equal(t, 79.26, d.Float64())
+
+	d, _ = ParseDecimal("0.005", 2)
+	assert.Equal(t, Decimal{1, 2}, d)
+
+	d, _ = ParseDecimal("-1", 2)
+	assert.Equal(t, "-1.00", d.String())
+
+	for _, s := range []string{"", "abc", "1.2.3", "1.x"} {
+		_, err = ParseDecimal(s, 2)
+		assert.IsType(t, &ErrParsingDecimal{}, err, s)
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	a, _ := ParseDecimal("79.26", 2)
+	b, _ := ParseDecimal("0.0125", 4)
+
+	assert.Equal(t, "79.2725", a.Add(b).String())
+	assert.Equal(t, "79.2475", a.Sub(b).String())
+	assert.Equal(t, "7926.00", a.MulInt(100).String())
+	assert.Equal(t, 1, a.Cmp(b))
+	assert.Equal(t, Decimal{7927, 2}, a.Add(b).Rescale(2))
+	assert.Equal(t, uint8(2), scaleOfTickSize(0.01))
+	assert.Equal(t, uint8(2), scaleOfTickSize(0.25))
+	assert.Equal(t, uint8(0), scaleOfTickSize(1))
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	candle := Candle{Symbol: "PSCC", Close: 79.26, Exact: &CandleDecimals{Close: Decimal{792600, 4}}}
+
+	json_, err := json.Marshal(candle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(json_), `"Close":79.2600`)
+
+	loaded := Candle{}
+	err = json.Unmarshal(json_, &loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, *candle.Exact, *loaded.Exact)
+
+	json_, _ = json.Marshal(Candle{})
+	assert.NotContains(t, string(json_), "Exact")
+}
+
+func TestActiveTick_parseDecimalPrices(t *testing.T) {
+	ticks, err := parseToTQ("T,20181101093000551,79.260000,100,Q,0,0,0,0\r\nQ,20181101093000551,79.250000,79.270000,1,2,P,P,0\r\n", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, Decimal{7926, 2}, ticks[0].Exact.LastPrice)
+	assert.Equal(t, Decimal{-100, 2}, ticks[0].Exact.BidPrice)
+	assert.Equal(t, Decimal{7927, 2}, ticks[1].Exact.AskPrice)
+
+	candles, err := parseToCandlesList("20181101000000,271.600000,273.730000,270.380000,273.370000,89496311\r\n", "SPY", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "273.37", candles[0].Exact.AdjClose.String())
+
+	at := NewActiveTick(5000, "127.0.0.1", 2, "")
+	assert.Equal(t, noDecimals, at.priceScale("SPY"))
+
+	at.SetDecimalPrices(6)
+	registry := NewInstrumentRegistry("")
+	registry.Add(Instrument{Symbol: "ES", TickSize: 0.25})
+	at.SetInstruments(registry)
+	assert.Equal(t, 6, at.priceScale("SPY"))
+	assert.Equal(t, 2, at.priceScale("ES"))
+}
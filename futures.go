@@ -0,0 +1,369 @@
+package marketdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	futuresMonthCodes = "FGHJKMNQUVXZ"
+	continuousSuffix  = "_CONT"
+	rollsFolder       = ".rolls"
+)
+
+type ErrParsingFuturesSymbol struct {
+	symbol string
+}
+
+func (e *ErrParsingFuturesSymbol) Error() string {
+	return fmt.Sprintf("Can't parse futures symbol: %q", e.symbol)
+}
+
+type FuturesContract struct {
+	Root   string
+	Month  time.Month
+	Year   int
+	Expiry time.Time
+}
+
+// Month code of contract: F for January ... Z for December
+func (f *FuturesContract) MonthCode() string {
+	if f.Month < time.January || f.Month > time.December {
+		return ""
+	}
+	return string(futuresMonthCodes[f.Month-1])
+}
+
+// Symbol with month code and 2 digit year: ESZ18
+func (f *FuturesContract) Symbol() string {
+	return fmt.Sprintf("%v%v%02d", strings.ToUpper(f.Root), f.MonthCode(), f.Year%100)
+}
+
+// Parses symbols like ESZ18 or ESZ2018. Expiry is not part of symbol and stays zero
+func ParseFuturesSymbol(symbol string) (*FuturesContract, error) {
+	s := strings.ToUpper(strings.TrimSpace(symbol))
+
+	digits := 0
+	for digits < len(s) && s[len(s)-1-digits] >= '0' && s[len(s)-1-digits] <= '9' {
+		digits++
+	}
+	if (digits != 2 && digits != 4) || len(s) < digits+2 {
+		return nil, &ErrParsingFuturesSymbol{symbol}
+	}
+
+	year, err := strconv.Atoi(s[len(s)-digits:])
+	if err != nil {
+		return nil, &ErrParsingFuturesSymbol{symbol}
+	}
+	if digits == 2 {
+		year += 2000
+	}
+
+	code := s[len(s)-digits-1]
+	month := strings.IndexByte(futuresMonthCodes, code)
+	if month < 0 {
+		return nil, &ErrParsingFuturesSymbol{symbol}
+	}
+
+	contract := FuturesContract{
+		Root:  s[:len(s)-digits-1],
+		Month: time.Month(month + 1),
+		Year:  year,
+	}
+	return &contract, nil
+}
+
+type RollMethod string
+
+const (
+	RollFixedDays    RollMethod = "fixed_days"    // Roll DaysBeforeExpiry calendar days before expiry
+	RollVolume       RollMethod = "volume"        // Roll when next contract volume exceeds front one
+	RollOpenInterest RollMethod = "open_interest" // Roll when next contract open interest exceeds front one
+)
+
+type BackAdjustment string
+
+const (
+	NoAdjustment         BackAdjustment = "none"
+	DifferenceAdjustment BackAdjustment = "difference"
+	RatioAdjustment      BackAdjustment = "ratio"
+)
+
+type RollRule struct {
+	Method           RollMethod
+	DaysBeforeExpiry int
+}
+
+// Single roll From one contract To another. Prices are closes of both contracts on the last day before roll
+type Roll struct {
+	Date      time.Time
+	From      string
+	To        string
+	FromClose float64
+	ToClose   float64
+}
+
+// Roll schedule used To build continuous series
+type ContinuousSeries struct {
+	Symbol     string
+	Rule       RollRule
+	Adjustment BackAdjustment
+	Rolls      []Roll
+}
+
+func (c *ContinuousSeries) save(savePath string) error {
+	err := createDirIfNotExists(filepath.Dir(savePath))
+	if err != nil {
+		return err
+	}
+
+	json_, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(savePath, json_, 0644)
+}
+
+func (c *ContinuousSeries) Load(loadPath string) error {
+	byteValue, err := ioutil.ReadFile(loadPath)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(byteValue, c)
+}
+
+func ContinuousSymbol(root string) string {
+	return strings.ToUpper(root) + continuousSuffix
+}
+
+// Stitches daily candles of contracts into continuous series. Candles are taken From map by contract symbol.
+// Earlier prices are back-adjusted, so the latest prices are equal To front contract ones. Returns series candles and
+// roll schedule.
+func BuildContinuousCandles(contracts []FuturesContract, candles map[string]CandleArray, rule RollRule,
+	adjustment BackAdjustment) (CandleArray, *ContinuousSeries, error) {
+
+	if len(contracts) == 0 {
+		return nil, nil, errors.New("No contracts To build continuous series")
+	}
+
+	sorted := make([]FuturesContract, len(contracts))
+	copy(sorted, contracts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return contractOrder(&sorted[i]) < contractOrder(&sorted[j])
+	})
+
+	series := ContinuousSeries{
+		Symbol:     ContinuousSymbol(sorted[0].Root),
+		Rule:       rule,
+		Adjustment: adjustment,
+	}
+
+	var segments []CandleArray
+	var start time.Time
+
+	for i := range sorted {
+		front := sorted[i]
+		frontCandles := sortedCopy(candles[front.Symbol()])
+
+		end := time.Time{}
+		if i < len(sorted)-1 {
+			next := sorted[i+1]
+			nextCandles := sortedCopy(candles[next.Symbol()])
+
+			rollDate, err := findRollDate(&front, frontCandles, nextCandles, rule)
+			if err != nil {
+				return nil, nil, err
+			}
+			end = rollDate
+
+			fromClose, toClose := closesBefore(frontCandles, nextCandles, rollDate)
+			series.Rolls = append(series.Rolls, Roll{rollDate, front.Symbol(), next.Symbol(), fromClose, toClose})
+		}
+
+		var segment CandleArray
+		for _, c := range frontCandles {
+			if c.Datetime.Before(start) || (!end.IsZero() && !c.Datetime.Before(end)) {
+				continue
+			}
+			candle := *c
+			candle.Symbol = series.Symbol
+			segment = append(segment, &candle)
+		}
+		segments = append(segments, segment)
+		start = end
+	}
+
+	// Back adjustment goes From the latest roll To the earliest one
+	for i := len(series.Rolls) - 1; i >= 0; i-- {
+		roll := series.Rolls[i]
+		for _, segment := range segments[:i+1] {
+			adjustSegment(segment, roll, adjustment)
+		}
+	}
+
+	var continuous CandleArray
+	for _, segment := range segments {
+		continuous = append(continuous, segment...)
+	}
+
+	return continuous, &series, nil
+}
+
+func contractOrder(f *FuturesContract) int64 {
+	if !f.Expiry.IsZero() {
+		return f.Expiry.Unix()
+	}
+	return time.Date(f.Year, f.Month, 1, 0, 0, 0, 0, time.UTC).Unix()
+}
+
+func sortedCopy(candles CandleArray) CandleArray {
+	sorted := make(CandleArray, len(candles))
+	copy(sorted, candles)
+	sorted.Sort()
+	return sorted
+}
+
+func findRollDate(front *FuturesContract, frontCandles CandleArray, nextCandles CandleArray,
+	rule RollRule) (time.Time, error) {
+
+	switch rule.Method {
+	case RollFixedDays:
+		if front.Expiry.IsZero() {
+			return time.Time{}, errors.New("Expiry is not set for " + front.Symbol())
+		}
+		return setTimeToSOD(front.Expiry).AddDate(0, 0, -rule.DaysBeforeExpiry), nil
+
+	case RollVolume, RollOpenInterest:
+		nextByDate := make(map[int64]*Candle)
+		for _, c := range nextCandles {
+			nextByDate[setTimeToSOD(c.Datetime).Unix()] = c
+		}
+
+		for _, c := range frontCandles {
+			next, ok := nextByDate[setTimeToSOD(c.Datetime).Unix()]
+			if !ok {
+				continue
+			}
+			if rule.Method == RollVolume && next.Volume > c.Volume {
+				return setTimeToSOD(c.Datetime).AddDate(0, 0, 1), nil
+			}
+			if rule.Method == RollOpenInterest && next.OpenInterest > c.OpenInterest {
+				return setTimeToSOD(c.Datetime).AddDate(0, 0, 1), nil
+			}
+		}
+
+		// No crossover. Roll after the last front candle
+		if len(frontCandles) > 0 {
+			return setTimeToSOD(frontCandles[len(frontCandles)-1].Datetime).AddDate(0, 0, 1), nil
+		}
+		if !front.Expiry.IsZero() {
+			return setTimeToSOD(front.Expiry), nil
+		}
+		return time.Time{}, errors.New("No candles To find roll date for " + front.Symbol())
+
+	default:
+		return time.Time{}, errors.New("Unknown roll method: " + string(rule.Method))
+	}
+}
+
+// Closes of both contracts on the latest common date before roll date
+func closesBefore(frontCandles CandleArray, nextCandles CandleArray, rollDate time.Time) (float64, float64) {
+	nextByDate := make(map[int64]*Candle)
+	for _, c := range nextCandles {
+		nextByDate[setTimeToSOD(c.Datetime).Unix()] = c
+	}
+
+	for i := len(frontCandles) - 1; i >= 0; i-- {
+		c := frontCandles[i]
+		if !c.Datetime.Before(rollDate) {
+			continue
+		}
+		if next, ok := nextByDate[setTimeToSOD(c.Datetime).Unix()]; ok {
+			return c.Close, next.Close
+		}
+	}
+
+	return 0, 0
+}
+
+func adjustSegment(segment CandleArray, roll Roll, adjustment BackAdjustment) {
+	if roll.FromClose <= 0 || roll.ToClose <= 0 {
+		return
+	}
+
+	for _, c := range segment {
+		switch adjustment {
+		case DifferenceAdjustment:
+			diff := roll.ToClose - roll.FromClose
+			c.Open += diff
+			c.High += diff
+			c.Low += diff
+			c.Close += diff
+			c.AdjClose += diff
+		case RatioAdjustment:
+			ratio := roll.ToClose / roll.FromClose
+			c.Open *= ratio
+			c.High *= ratio
+			c.Low *= ratio
+			c.Close *= ratio
+			c.AdjClose *= ratio
+		}
+		c.syncExact()
+	}
+}
+
+// Builds continuous daily candles From stored contract candles. Series is saved as usual daily candles of
+// ContinuousSymbol(root) and roll schedule is saved To candles/day/.rolls
+func (p *JsonStorage) UpdateContinuousCandles(contracts []FuturesContract, rule RollRule,
+	adjustment BackAdjustment) (CandleArray, error) {
+
+	candles := make(map[string]CandleArray)
+	for _, c := range contracts {
+		stored, err := p.GetStoredCandles(c.Symbol(), "D", DateRange{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "UpdateContinuousCandles() contract: %v", c.Symbol())
+		}
+		candles[c.Symbol()] = stored
+	}
+
+	continuous, series, err := BuildContinuousCandles(contracts, candles, rule, adjustment)
+	if err != nil {
+		return nil, err
+	}
+
+	updateLock, err := p.lockSymbolUpdate(series.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	defer updateLock.Unlock()
+
+	dataLock, err := p.lockSymbolData(series.Symbol, true)
+	if err != nil {
+		return nil, err
+	}
+	defer dataLock.Unlock()
+
+	savePath := path.Join(p.Path, "candles/day", series.Symbol+".json")
+	err = p.saveCandlesToFile(&continuous, savePath)
+	if err != nil {
+		return nil, err
+	}
+
+	rollsPath := path.Join(p.Path, "candles/day", rollsFolder, series.Symbol+".json")
+	err = series.save(rollsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return continuous, nil
+}
@@ -0,0 +1,127 @@
+package marketdata
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFuturesSymbol(t *testing.T) {
+	contract, err := ParseFuturesSymbol("ESZ18")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, FuturesContract{Root: "ES", Month: time.December, Year: 2018}, *contract)
+	assert.Equal(t, "ESZ18", contract.Symbol())
+
+	contract, err = ParseFuturesSymbol("CLF2019")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "CLF19", contract.Symbol())
+
+	for _, s := range []string{"SPY", "ESA18", "Z18", "ESZ1"} {
+		_, err = ParseFuturesSymbol(s)
+		assert.IsType(t, &ErrParsingFuturesSymbol{}, err, s)
+	}
+}
+
+func futuresCandlesMock(symbol string, from time.Time, days int, close_ float64, volume int64) CandleArray {
+	var candles CandleArray
+	for i := 0; i < days; i++ {
+		candles = append(candles, &Candle{
+			Symbol:   symbol,
+			Open:     close_,
+			High:     close_,
+			Low:      close_,
+			Close:    close_,
+			AdjClose: close_,
+			Volume:   volume + int64(i),
+			Datetime: from.AddDate(0, 0, i),
+		})
+	}
+	return candles
+}
+
+func TestBuildContinuousCandles(t *testing.T) {
+	z18 := FuturesContract{"ES", time.December, 2018, timeOnTheFly(2018, 12, 21)}
+	h19 := FuturesContract{"ES", time.March, 2019, timeOnTheFly(2019, 3, 15)}
+
+	candles := map[string]CandleArray{
+		"ESZ18": futuresCandlesMock("ESZ18", timeOnTheFly(2018, 12, 10), 12, 100, 1000),
+		"ESH19": futuresCandlesMock("ESH19", timeOnTheFly(2018, 12, 10), 20, 110, 995),
+	}
+
+	continuous, series, err := BuildContinuousCandles([]FuturesContract{h19, z18}, candles,
+		RollRule{RollFixedDays, 5}, DifferenceAdjustment)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "ES_CONT", series.Symbol)
+	assert.Equal(t, 1, len(series.Rolls))
+	assert.Equal(t, timeOnTheFly(2018, 12, 16), series.Rolls[0].Date)
+	assert.Equal(t, 20, len(continuous))
+	assert.Equal(t, 110.0, continuous[0].Close)
+	assert.Equal(t, int64(1000), continuous[0].Volume)
+	assert.Equal(t, int64(1001), continuous[6].Volume)
+
+	// Volume crossover: next contract volume is bigger From the 7th day
+	for i, c := range candles["ESH19"] {
+		c.Volume = 990 + 3*int64(i)
+	}
+	_, series, err = BuildContinuousCandles([]FuturesContract{z18, h19}, candles, RollRule{Method: RollVolume},
+		RatioAdjustment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, timeOnTheFly(2018, 12, 17), series.Rolls[0].Date)
+
+	// Raw candles are not modified
+	assert.Equal(t, 100.0, candles["ESZ18"][0].Close)
+}
+
+func TestJsonStorage_UpdateContinuousCandles(t *testing.T) {
+	testDir := "./test_data/continuous_test"
+	defer os.RemoveAll(testDir)
+
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC}
+	_, err := storage.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	z18 := FuturesContract{"ES", time.December, 2018, timeOnTheFly(2018, 12, 21)}
+	h19 := FuturesContract{"ES", time.March, 2019, timeOnTheFly(2019, 3, 15)}
+	for symbol, c := range map[string]CandleArray{
+		"ESZ18": futuresCandlesMock("ESZ18", timeOnTheFly(2018, 12, 10), 12, 100, 1000),
+		"ESH19": futuresCandlesMock("ESH19", timeOnTheFly(2018, 12, 10), 20, 110, 995),
+	} {
+		err := storage.saveCandlesToFile(&c, path.Join(testDir, "candles/day", symbol+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err = storage.UpdateContinuousCandles([]FuturesContract{z18, h19}, RollRule{RollFixedDays, 5}, RatioAdjustment)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := storage.GetStoredCandles("ES_CONT", "D", DateRange{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 20, len(stored))
+
+	series := ContinuousSeries{}
+	err = series.Load(path.Join(testDir, "candles/day/.rolls/ES_CONT.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "ESZ18", series.Rolls[0].From)
+	assert.Equal(t, RatioAdjustment, series.Adjustment)
+}
@@ -0,0 +1,180 @@
+//go:build grpc
+
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Client of MarketData service. It implements HistoryProvider, so remote storage or provider can be used as
+// JsonStorage.Provider:
+//
+//	conn, _ := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+//	storage.Provider = NewGRPCClient(conn, true)
+type GRPCClient struct {
+	Stored    bool          // Request server storage. Server provider is requested otherwise
+	BatchSize int           // Ticks in one stream message. 0 means server default
+	Timeout   time.Duration // Timeout of HistoryProvider calls. 0 means no timeout
+
+	client MarketDataClient
+}
+
+func NewGRPCClient(conn grpc.ClientConnInterface, stored bool) *GRPCClient {
+	return &GRPCClient{Stored: stored, client: NewMarketDataClient(conn)}
+}
+
+// Live ticks received by StreamTicks
+type TickBatch struct {
+	Ticks   TickArray
+	Dropped int64 // Live ticks dropped for slow client since subscription
+}
+
+// Remote server has no requested data. Unlike ErrEmptyResponse it doesn't mean that there is no data at all, so
+// storage updates don't record it as empty day
+type ErrRemoteNotFound struct {
+	request string
+	message string
+}
+
+func (e *ErrRemoteNotFound) Error() string {
+	return fmt.Sprintf("Remote server has no %v: %v", e.request, e.message)
+}
+
+// Converts grpc status To package errors
+func fromGRPCError(err error, request string) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch st.Code() {
+	case codes.NotFound:
+		return &ErrRemoteNotFound{request, st.Message()}
+	case codes.Unavailable:
+		return &ErrDatasourceNotConnected{"grpc server"}
+	}
+	return errors.New(st.Message())
+}
+
+func (c *GRPCClient) callContext() (context.Context, context.CancelFunc) {
+	if c.Timeout > 0 {
+		return context.WithTimeout(context.Background(), c.Timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+func (c *GRPCClient) GetCandles(symbol string, timeframe string, dRange DateRange) (CandleArray, error) {
+	ctx, cancel := c.callContext()
+	defer cancel()
+	return c.GetCandlesContext(ctx, symbol, timeframe, dRange)
+}
+
+func (c *GRPCClient) GetCandlesContext(ctx context.Context, symbol string, timeframe string,
+	dRange DateRange) (CandleArray, error) {
+
+	req := CandlesRequest{Symbol: symbol, TimeFrame: timeframe, Range: rangeToProto(dRange), Stored: c.Stored}
+	resp, err := c.client.GetCandles(ctx, &req)
+	if err != nil {
+		return nil, fromGRPCError(err, symbol+" candles")
+	}
+	if len(resp.Candles) == 0 {
+		return nil, &ErrEmptyResponse{symbol + " candles"}
+	}
+	return candlesFromProto(resp.Candles), nil
+}
+
+func (c *GRPCClient) GetTicks(symbol string, dRange DateRange, quotes bool, trades bool) (TickArray, error) {
+	ctx, cancel := c.callContext()
+	defer cancel()
+
+	var ticks TickArray
+	err := c.ReplayTicks(ctx, symbol, dRange, quotes, trades, func(batch TickArray) error {
+		ticks = append(ticks, batch...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(ticks) == 0 {
+		return nil, &ErrEmptyResponse{symbol + " ticks"}
+	}
+	return ticks, nil
+}
+
+// Streams historical ticks. Handler is called for every received batch, its error stops replay
+func (c *GRPCClient) ReplayTicks(ctx context.Context, symbol string, dRange DateRange, quotes bool, trades bool,
+	handler func(TickArray) error) error {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req := TicksRequest{Symbol: symbol, Range: rangeToProto(dRange), Quotes: quotes, Trades: trades,
+		Stored: c.Stored, BatchSize: int32(c.BatchSize)}
+	stream, err := c.client.ReplayTicks(ctx, &req)
+	if err != nil {
+		return fromGRPCError(err, symbol+" ticks")
+	}
+
+	for {
+		batch, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fromGRPCError(err, symbol+" ticks")
+		}
+		err = handler(ticksFromProto(batch.Ticks))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Streams live ticks of symbols until context is cancelled or handler returns error. All symbols are streamed if
+// symbols aren't set
+func (c *GRPCClient) StreamTicks(ctx context.Context, symbols []string, handler func(TickBatch) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.client.StreamTicks(ctx, &SubscribeRequest{Symbols: symbols, BatchSize: int32(c.BatchSize)})
+	if err != nil {
+		return fromGRPCError(err, "live ticks")
+	}
+
+	for {
+		batch, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fromGRPCError(err, "live ticks")
+		}
+		err = handler(TickBatch{ticksFromProto(batch.Ticks), batch.Dropped})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (c *GRPCClient) GetSnapshot(ctx context.Context, symbol string) (*TickSnapshot, error) {
+	snap, err := c.client.GetSnapshot(ctx, &SnapshotRequest{Symbol: symbol})
+	if err != nil {
+		return nil, fromGRPCError(err, symbol+" snapshot")
+	}
+	return &TickSnapshot{
+		Symbol:    snap.Symbol,
+		LastTrade: tickFromProto(snap.LastTrade),
+		LastQuote: tickFromProto(snap.LastQuote),
+		Updated:   timeFromProto(snap.Updated),
+	}, nil
+}
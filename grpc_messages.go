@@ -0,0 +1,189 @@
+//go:build grpc
+
+package marketdata
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Conversions between generated messages of marketdata.proto and package types. Times come back in UTC
+
+// Unset timestamp is zero time, not Unix epoch
+func timeFromProto(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func rangeToProto(dRange DateRange) *TimeRange {
+	return &TimeRange{From: timestamppb.New(dRange.From), To: timestamppb.New(dRange.To)}
+}
+
+func rangeFromProto(r *TimeRange) DateRange {
+	return DateRange{timeFromProto(r.GetFrom()), timeFromProto(r.GetTo())}
+}
+
+func decimalToProto(d Decimal) *DecimalData {
+	return &DecimalData{Units: d.Units, Scale: uint32(d.Scale)}
+}
+
+func decimalFromProto(d *DecimalData) Decimal {
+	return Decimal{d.GetUnits(), uint8(d.GetScale())}
+}
+
+func candleToProto(c *Candle) *CandleData {
+	m := &CandleData{
+		Symbol:       c.Symbol,
+		Open:         c.Open,
+		High:         c.High,
+		Low:          c.Low,
+		Close:        c.Close,
+		AdjClose:     c.AdjClose,
+		Volume:       c.Volume,
+		OpenInterest: c.OpenInterest,
+		Datetime:     timestamppb.New(c.Datetime),
+		Source:       c.Source,
+	}
+	if c.Exact != nil {
+		m.Exact = &CandleDecimalsData{
+			Open:     decimalToProto(c.Exact.Open),
+			High:     decimalToProto(c.Exact.High),
+			Low:      decimalToProto(c.Exact.Low),
+			Close:    decimalToProto(c.Exact.Close),
+			AdjClose: decimalToProto(c.Exact.AdjClose),
+		}
+	}
+	return m
+}
+
+func candleFromProto(m *CandleData) *Candle {
+	c := &Candle{
+		Symbol:       m.Symbol,
+		Open:         m.Open,
+		High:         m.High,
+		Low:          m.Low,
+		Close:        m.Close,
+		AdjClose:     m.AdjClose,
+		Volume:       m.Volume,
+		OpenInterest: m.OpenInterest,
+		Datetime:     timeFromProto(m.Datetime),
+		Source:       m.Source,
+	}
+	if m.Exact != nil {
+		c.Exact = &CandleDecimals{
+			Open:     decimalFromProto(m.Exact.Open),
+			High:     decimalFromProto(m.Exact.High),
+			Low:      decimalFromProto(m.Exact.Low),
+			Close:    decimalFromProto(m.Exact.Close),
+			AdjClose: decimalFromProto(m.Exact.AdjClose),
+		}
+	}
+	return c
+}
+
+func candlesToProto(candles CandleArray) []*CandleData {
+	messages := make([]*CandleData, len(candles))
+	for i, c := range candles {
+		messages[i] = candleToProto(c)
+	}
+	return messages
+}
+
+func candlesFromProto(messages []*CandleData) CandleArray {
+	candles := make(CandleArray, len(messages))
+	for i, m := range messages {
+		candles[i] = candleFromProto(m)
+	}
+	return candles
+}
+
+func tickToProto(t *Tick) *TickData {
+	if t == nil {
+		return nil
+	}
+	m := &TickData{
+		Symbol:    t.Symbol,
+		IsOpening: t.IsOpening,
+		IsClosing: t.IsClosing,
+		LastPrice: t.LastPrice,
+		LastSize:  t.LastSize,
+		LastExch:  t.LastExch,
+		Datetime:  timestamppb.New(t.Datetime),
+		Seq:       t.Seq,
+		BidExch:   t.BidExch,
+		AskExch:   t.AskExch,
+		BidPrice:  t.BidPrice,
+		AskPrice:  t.AskPrice,
+		BidSize:   t.BidSize,
+		AskSize:   t.AskSize,
+		CondQuote: t.CondQuote,
+		Cond1:     t.Cond1,
+		Cond2:     t.Cond2,
+		Cond3:     t.Cond3,
+		Cond4:     t.Cond4,
+		Source:    t.Source,
+	}
+	if t.Exact != nil {
+		m.Exact = &TickDecimalsData{
+			LastPrice: decimalToProto(t.Exact.LastPrice),
+			BidPrice:  decimalToProto(t.Exact.BidPrice),
+			AskPrice:  decimalToProto(t.Exact.AskPrice),
+		}
+	}
+	return m
+}
+
+func tickFromProto(m *TickData) *Tick {
+	if m == nil {
+		return nil
+	}
+	t := &Tick{
+		Symbol:    m.Symbol,
+		IsOpening: m.IsOpening,
+		IsClosing: m.IsClosing,
+		LastPrice: m.LastPrice,
+		LastSize:  m.LastSize,
+		LastExch:  m.LastExch,
+		Datetime:  timeFromProto(m.Datetime),
+		Seq:       m.Seq,
+		BidExch:   m.BidExch,
+		AskExch:   m.AskExch,
+		BidPrice:  m.BidPrice,
+		AskPrice:  m.AskPrice,
+		BidSize:   m.BidSize,
+		AskSize:   m.AskSize,
+		CondQuote: m.CondQuote,
+		Cond1:     m.Cond1,
+		Cond2:     m.Cond2,
+		Cond3:     m.Cond3,
+		Cond4:     m.Cond4,
+		Source:    m.Source,
+	}
+	if m.Exact != nil {
+		t.Exact = &TickDecimals{
+			LastPrice: decimalFromProto(m.Exact.LastPrice),
+			BidPrice:  decimalFromProto(m.Exact.BidPrice),
+			AskPrice:  decimalFromProto(m.Exact.AskPrice),
+		}
+	}
+	return t
+}
+
+func ticksToProto(ticks TickArray) []*TickData {
+	messages := make([]*TickData, len(ticks))
+	for i, t := range ticks {
+		messages[i] = tickToProto(t)
+	}
+	return messages
+}
+
+func ticksFromProto(messages []*TickData) TickArray {
+	ticks := make(TickArray, len(messages))
+	for i, m := range messages {
+		ticks[i] = tickFromProto(m)
+	}
+	return ticks
+}
@@ -0,0 +1,220 @@
+//go:build grpc
+
+package marketdata
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// gRPC transport is built only with grpc tag, so package doesn't depend on grpc by default.
+//
+// Service marketdata.MarketData is described in marketdata.proto, stubs of other languages can be generated From
+// it. Empty response of provider is sent as empty result. NotFound status means that remote storage has no
+// requested data, GRPCClient returns ErrRemoteNotFound for it.
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative marketdata.proto
+//go:generate sed -i "1i //go:build grpc\\n" marketdata.pb.go marketdata_grpc.pb.go
+
+const DefaultGRPCBatchSize = 1000
+
+// Serves storage, provider and live ticks. Any of them can be nil, its RPCs return Unimplemented then
+type GRPCServer struct {
+	UnimplementedMarketDataServer
+
+	Storage  Storage
+	Provider HistoryProvider
+	Live     LiveTickSource
+}
+
+func NewGRPCServer(storage Storage, provider HistoryProvider, live LiveTickSource) *GRPCServer {
+	return &GRPCServer{Storage: storage, Provider: provider, Live: live}
+}
+
+// Registers service on grpc server
+func (s *GRPCServer) Register(g grpc.ServiceRegistrar) {
+	RegisterMarketDataServer(g, s)
+}
+
+// Converts package errors To grpc status
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch errors.Cause(err).(type) {
+	case *ErrSymbolDataNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case *ErrLockTimeout:
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	if errors.Cause(err) == context.Canceled {
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return status.Error(codes.Unknown, err.Error())
+}
+
+func (s *GRPCServer) GetCandles(ctx context.Context, req *CandlesRequest) (*CandlesResponse, error) {
+	var candles CandleArray
+	var err error
+	dRange := rangeFromProto(req.Range)
+	if req.Stored {
+		if s.Storage == nil {
+			return nil, status.Error(codes.Unimplemented, "Server has no storage")
+		}
+		candles, err = s.Storage.GetStoredCandles(req.Symbol, req.TimeFrame, dRange)
+		candles = candlesInRange(candles, dRange)
+	} else {
+		if s.Provider == nil {
+			return nil, status.Error(codes.Unimplemented, "Server has no provider")
+		}
+		candles, err = s.Provider.GetCandles(req.Symbol, req.TimeFrame, dRange)
+	}
+	if _, ok := errors.Cause(err).(*ErrEmptyResponse); ok {
+		return &CandlesResponse{}, nil
+	}
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &CandlesResponse{Candles: candlesToProto(candles)}, nil
+}
+
+func (s *GRPCServer) GetSnapshot(ctx context.Context, req *SnapshotRequest) (*SnapshotResponse, error) {
+	if s.Live == nil {
+		return nil, status.Error(codes.Unimplemented, "Server has no live ticks")
+	}
+	snap, ok := s.Live.Snapshot(req.Symbol)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "No live ticks of %v", req.Symbol)
+	}
+	return &SnapshotResponse{
+		Symbol:    snap.Symbol,
+		LastTrade: tickToProto(snap.LastTrade),
+		LastQuote: tickToProto(snap.LastQuote),
+		Updated:   timestamppb.New(snap.Updated),
+	}, nil
+}
+
+func grpcBatchSize(n int32) int {
+	if n <= 0 {
+		return DefaultGRPCBatchSize
+	}
+	return int(n)
+}
+
+// Sends historical ticks in batches
+func (s *GRPCServer) ReplayTicks(req *TicksRequest, stream MarketData_ReplayTicksServer) error {
+	size := grpcBatchSize(req.BatchSize)
+	if req.Stored {
+		if s.Storage == nil {
+			return status.Error(codes.Unimplemented, "Server has no storage")
+		}
+		return s.replayStoredTicks(req, size, stream)
+	}
+
+	if s.Provider == nil {
+		return status.Error(codes.Unimplemented, "Server has no provider")
+	}
+	ticks, err := s.Provider.GetTicks(req.Symbol, rangeFromProto(req.Range), req.Quotes, req.Trades)
+	if _, ok := errors.Cause(err).(*ErrEmptyResponse); ok {
+		return nil
+	}
+	if err != nil {
+		return grpcError(err)
+	}
+
+	for start := 0; start < len(ticks); start += size {
+		end := start + size
+		if end > len(ticks) {
+			end = len(ticks)
+		}
+		err = stream.Send(&TicksResponse{Ticks: ticksToProto(ticks[start:end])})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stored ticks are read day by day, so long ranges aren't loaded into memory
+func (s *GRPCServer) replayStoredTicks(req *TicksRequest, size int, stream MarketData_ReplayTicksServer) error {
+	dRange := rangeFromProto(req.Range)
+	// Days which weren't downloaded are not found rather than empty
+	if storage, ok := s.Storage.(*JsonStorage); ok {
+		if missing := storage.unlistedTickDates(req.Symbol, dRange, req.Quotes, req.Trades); len(missing) > 0 {
+			return status.Errorf(codes.NotFound, "%v ticks of %v are not stored", req.Symbol,
+				missing[0].Format("2006-01-02"))
+		}
+	}
+
+	it := NewTickIterator(s.Storage, req.Symbol, dRange, req.Quotes, req.Trades, IteratorOptions{Prefetch: 1})
+	defer it.Close()
+
+	batch := make(TickArray, 0, size)
+	for it.Next() {
+		batch = append(batch, it.Tick())
+		if len(batch) < size {
+			continue
+		}
+		// Batch is copied To message, so it can be reused
+		err := stream.Send(&TicksResponse{Ticks: ticksToProto(batch)})
+		if err != nil {
+			return err
+		}
+		batch = batch[:0]
+	}
+	if it.Err() != nil {
+		return grpcError(it.Err())
+	}
+	if len(batch) > 0 {
+		return stream.Send(&TicksResponse{Ticks: ticksToProto(batch)})
+	}
+	return nil
+}
+
+// Sends live ticks until client cancels. Ticks are sent as soon as they come, ticks which are already waiting
+// are sent in one batch
+func (s *GRPCServer) StreamTicks(req *SubscribeRequest, stream MarketData_StreamTicksServer) error {
+	if s.Live == nil {
+		return status.Error(codes.Unimplemented, "Server has no live ticks")
+	}
+	sub := s.Live.Subscribe(req.Symbols...)
+	defer sub.Close()
+
+	size := grpcBatchSize(req.BatchSize)
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return grpcError(ctx.Err())
+		case tick, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			batch := TickArray{tick}
+		collect:
+			for len(batch) < size {
+				select {
+				case tick, ok := <-sub.C:
+					if !ok {
+						break collect
+					}
+					batch = append(batch, tick)
+				default:
+					break collect
+				}
+			}
+			err := stream.Send(&TicksResponse{Ticks: ticksToProto(batch), Dropped: sub.Dropped()})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
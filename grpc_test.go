@@ -0,0 +1,173 @@
+//go:build grpc
+
+package marketdata
+
+import (
+	"context"
+	"net"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func startGRPCServer(t *testing.T, server *GRPCServer) (*grpc.ClientConn, func()) {
+	listener := bufconn.Listen(1 << 20)
+	g := grpc.NewServer()
+	server.Register(g)
+	go g.Serve(listener)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return listener.Dial()
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, func() {
+		conn.Close()
+		g.Stop()
+	}
+}
+
+func TestGRPCClient_RemoteStorage(t *testing.T) {
+	testDir := "./test_data/grpc_remote"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	upstream := &quotesTradesMock{}
+	remote := NewGRPCServer(nil, upstream, nil)
+	conn, stop := startGRPCServer(t, remote)
+	defer stop()
+
+	client := NewGRPCClient(conn, false)
+	client.BatchSize = 1
+
+	ticks, err := client.GetTicks("SPY", DateRange{timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 1)}, true, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ticks))
+
+	_, err = client.GetCandles("SPY", "D", DateRange{timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 2)})
+	assert.IsType(t, &ErrEmptyResponse{}, err)
+
+	// Remote provider plugged into local storage
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC, Market: WeekdaysMarket, Provider: client}
+	err = storage.UpdateSymbolTicks(TickUpdateParams{Symbol: "SPY", FromDate: timeOnTheFly(2018, 11, 1),
+		ToDate: timeOnTheFly(2018, 11, 2), Quotes: true, Trades: true})
+	assert.Nil(t, err)
+
+	stored, err := storage.GetStoredTicks("SPY", DateRange{timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 2)},
+		false, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(stored))
+
+	// Storage of server is requested
+	remote.Storage = &storage
+	client.Stored = true
+	ticks, err = client.GetTicks("SPY", DateRange{timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 2)}, true, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ticks))
+	assert.Equal(t, 3, len(upstream.requested))
+
+	// Stored candles are selected by range
+	candles := CandleArray{
+		&Candle{Symbol: "SPY", Close: 270, Datetime: timeOnTheFly(2018, 11, 1)},
+		&Candle{Symbol: "SPY", Close: 271, Datetime: timeOnTheFly(2018, 11, 2)},
+		&Candle{Symbol: "SPY", Close: 272, Datetime: timeOnTheFly(2018, 11, 5)},
+	}
+	err = storage.saveCandlesToFile(&candles, path.Join(testDir, "candles/day/SPY.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := client.GetCandles("SPY", "D", DateRange{timeOnTheFly(2018, 11, 2), timeOnTheFly(2018, 11, 5)})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(loaded))
+	assert.Equal(t, 271.0, loaded[0].Close)
+
+	client.BatchSize = 3
+	ticks, err = client.GetTicks("SPY", DateRange{timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 2)}, true, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(ticks))
+	assert.True(t, ticks[1].Datetime.Before(ticks[2].Datetime))
+
+	// Days which remote storage doesn't have aren't recorded as empty
+	_, err = client.GetTicks("SPY", DateRange{timeOnTheFly(2018, 11, 5), timeOnTheFly(2018, 11, 5)}, true, true)
+	assert.IsType(t, &ErrRemoteNotFound{}, err)
+
+	cacheDir := testDir + "_cache"
+	os.RemoveAll(cacheDir)
+	defer os.RemoveAll(cacheDir)
+	cache := JsonStorage{Path: cacheDir, TimeZone: time.UTC, Market: WeekdaysMarket, Provider: client}
+	err = cache.UpdateSymbolTicks(TickUpdateParams{Symbol: "SPY", FromDate: timeOnTheFly(2018, 11, 2),
+		ToDate: timeOnTheFly(2018, 11, 5), Quotes: true, Trades: true})
+	assert.NotNil(t, err)
+	coverage, err := cache.Coverage("SPY")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, coverage.Datasets["ticks/quotes_trades"].Days)
+}
+
+func TestGRPCClient_StreamTicks(t *testing.T) {
+	live := NewTickBroadcaster()
+	conn, stop := startGRPCServer(t, NewGRPCServer(nil, nil, live))
+	defer stop()
+	client := NewGRPCClient(conn, false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	received := make(chan *Tick, 10)
+	done := make(chan error)
+	go func() {
+		done <- client.StreamTicks(ctx, []string{"SPY"}, func(batch TickBatch) error {
+			for _, tick := range batch.Ticks {
+				received <- tick
+			}
+			return nil
+		})
+	}()
+
+	// Wait for subscription
+	for live.Subscribers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	live.Publish(tradeAt(0, 10))
+	live.Publish(&Tick{Symbol: "QQQ", LastPrice: 1, LastSize: 1})
+	live.Publish(quoteAt(1, 10, 10.01))
+
+	first := <-received
+	second := <-received
+	assert.Equal(t, 10.0, first.LastPrice)
+	assert.Equal(t, 10.01, second.AskPrice)
+
+	snap, err := client.GetSnapshot(ctx, "SPY")
+	assert.Nil(t, err)
+	assert.Equal(t, 10.0, snap.LastTrade.LastPrice)
+	assert.Equal(t, 10.01, snap.LastQuote.AskPrice)
+
+	_, err = client.GetSnapshot(ctx, "AAPL")
+	assert.IsType(t, &ErrRemoteNotFound{}, err)
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+}
+
+func TestGRPCMessages_RoundTrip(t *testing.T) {
+	tick := tradeAt(0, 10)
+	tick.Seq = 3
+	tick.Exact = &TickDecimals{LastPrice: Decimal{1000, 2}}
+	candle := &Candle{Symbol: "SPY", Close: 270, Volume: 5, Datetime: timeOnTheFly(2018, 11, 1),
+		Exact: &CandleDecimals{Close: Decimal{27000, 2}}}
+
+	assert.Equal(t, tick, tickFromProto(tickToProto(tick)))
+	assert.Equal(t, candle, candleFromProto(candleToProto(candle)))
+	assert.Nil(t, tickFromProto(tickToProto(nil)))
+
+	// Unset range stays zero
+	dRange := rangeFromProto(&TimeRange{})
+	assert.True(t, dRange.From.IsZero())
+	assert.True(t, dRange.To.IsZero())
+}
@@ -0,0 +1,496 @@
+package marketdata
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultTickPageSize = 100000
+	nextCursorHeader    = "X-Next-Cursor"
+	ndjsonFlushEvery    = 1000
+)
+
+// REST API over JsonStorage for services which can't use the package directly.
+//
+//	GET  /candles?symbol=SPY&timeframe=D&from=2018-11-01&to=2018-11-30
+//	GET  /ticks?symbol=SPY&from=2018-11-01&to=2018-11-02&quotes=true&trades=false&limit=1000&cursor=...
+//	GET  /coverage?symbol=SPY
+//	POST /update/candles?symbol=SPY&timeframe=D&from=2018-11-01&to=2018-11-30
+//	POST /update/ticks?symbol=SPY&from=2018-11-01&to=2018-11-02&quotes=true&trades=true&mode=best_effort
+//
+// Data is returned as json array by default, format=csv and format=ndjson are streamed. Responses are gzipped
+// if client accepts it. Tick responses are paginated: if there are more ticks than limit X-Next-Cursor header
+// is set and the next page is requested with cursor parameter.
+type HTTPServer struct {
+	Storage  *JsonStorage
+	PageSize int // Max ticks in page if limit isn't set. 0 means DefaultTickPageSize
+	mux      *http.ServeMux
+}
+
+func NewHTTPServer(storage *JsonStorage) *HTTPServer {
+	s := HTTPServer{Storage: storage, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/candles", s.method(http.MethodGet, s.handleCandles))
+	s.mux.HandleFunc("/ticks", s.method(http.MethodGet, s.handleTicks))
+	s.mux.HandleFunc("/coverage", s.method(http.MethodGet, s.handleCoverage))
+	s.mux.HandleFunc("/update/candles", s.method(http.MethodPost, s.handleUpdateCandles))
+	s.mux.HandleFunc("/update/ticks", s.method(http.MethodPost, s.handleUpdateTicks))
+	return &s
+}
+
+func (s *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *HTTPServer) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, s)
+}
+
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &httpError{http.StatusBadRequest, errors.Errorf(format, args...)}
+}
+
+func (s *HTTPServer) method(method string, h func(w http.ResponseWriter, r *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			writeHTTPError(w, &httpError{http.StatusMethodNotAllowed, errors.Errorf("%v expected", method)})
+			return
+		}
+		err := h(w, r)
+		if err != nil {
+			writeHTTPError(w, err)
+		}
+	}
+}
+
+func writeHTTPError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch e := errors.Cause(err).(type) {
+	case *httpError:
+		status = e.status
+	case *ErrSymbolDataNotFound:
+		status = http.StatusNotFound
+	case *ErrLockTimeout:
+		status = http.StatusServiceUnavailable
+	case *ErrTickUpdateFailed:
+		status = http.StatusBadGateway
+	}
+	writeJSON(w, status, struct{ Error string }{err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(v)
+}
+
+type dataRequest struct {
+	symbol string
+	dRange DateRange
+	format string
+}
+
+func parseDataRequest(r *http.Request) (*dataRequest, error) {
+	q := r.URL.Query()
+	req := dataRequest{symbol: q.Get("symbol"), format: q.Get("format")}
+	if req.symbol == "" {
+		return nil, badRequest("symbol is required")
+	}
+
+	var err error
+	req.dRange.From, err = time.Parse(tickfilelayout, q.Get("from"))
+	if err != nil {
+		return nil, badRequest("from should be date in %v format", tickfilelayout)
+	}
+	req.dRange.To, err = time.Parse(tickfilelayout, q.Get("to"))
+	if err != nil {
+		return nil, badRequest("to should be date in %v format", tickfilelayout)
+	}
+	if req.dRange.From.After(req.dRange.To) {
+		return nil, badRequest("from should be less than to")
+	}
+
+	switch req.format {
+	case "":
+		req.format = "json"
+	case "json", "csv", "ndjson":
+	default:
+		return nil, badRequest("Unknown format %q", req.format)
+	}
+	return &req, nil
+}
+
+func boolParam(r *http.Request, name string, def bool) (bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, badRequest("%v should be boolean", name)
+	}
+	return b, nil
+}
+
+func (s *HTTPServer) handleCandles(w http.ResponseWriter, r *http.Request) error {
+	req, err := parseDataRequest(r)
+	if err != nil {
+		return err
+	}
+	tf := r.URL.Query().Get("timeframe")
+	if tf == "" {
+		tf = "D"
+	}
+
+	candles, err := s.Storage.GetStoredCandles(req.symbol, tf, req.dRange)
+	if err != nil {
+		return err
+	}
+
+	selected := candlesInRange(candles, req.dRange)
+	return writeData(w, r, req.format, len(selected), func(i int) interface{} {
+		return selected[i]
+	}, candleCSVHeader, func(i int) []string {
+		return candleCSVRecord(selected[i])
+	})
+}
+
+// Stored file has all candles of symbol. Candles of range days are selected, the last day is included. Zero range
+// selects all candles
+func candlesInRange(candles CandleArray, dRange DateRange) CandleArray {
+	if dRange.From.IsZero() && dRange.To.IsZero() {
+		return candles
+	}
+	end := dRange.To.AddDate(0, 0, 1)
+	var selected CandleArray
+	for _, c := range candles {
+		if !c.Datetime.Before(dRange.From) && c.Datetime.Before(end) {
+			selected = append(selected, c)
+		}
+	}
+	return selected
+}
+
+// Position in tick range: day and index of tick in this day
+type tickCursor struct {
+	day   time.Time
+	index int
+}
+
+func (c *tickCursor) String() string {
+	return fmt.Sprintf("%v.%v", c.day.Format(tickfilelayout), c.index)
+}
+
+func parseTickCursor(s string) (*tickCursor, error) {
+	i := strings.LastIndex(s, ".")
+	if i < 0 {
+		return nil, badRequest("Wrong cursor %q", s)
+	}
+	day, err := time.Parse(tickfilelayout, s[:i])
+	if err != nil {
+		return nil, badRequest("Wrong cursor %q", s)
+	}
+	index, err := strconv.Atoi(s[i+1:])
+	if err != nil || index < 0 {
+		return nil, badRequest("Wrong cursor %q", s)
+	}
+	return &tickCursor{day, index}, nil
+}
+
+func (s *HTTPServer) handleTicks(w http.ResponseWriter, r *http.Request) error {
+	req, err := parseDataRequest(r)
+	if err != nil {
+		return err
+	}
+	quotes, err := boolParam(r, "quotes", true)
+	if err != nil {
+		return err
+	}
+	trades, err := boolParam(r, "trades", true)
+	if err != nil {
+		return err
+	}
+	if !quotes && !trades {
+		return badRequest("quotes, trades or both should be requested")
+	}
+
+	limit := s.PageSize
+	if limit <= 0 {
+		limit = DefaultTickPageSize
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return badRequest("limit should be positive number")
+		}
+	}
+
+	cursor := &tickCursor{req.dRange.From, 0}
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		cursor, err = parseTickCursor(v)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !s.hasTicks(req.symbol, quotes, trades) {
+		return &ErrSymbolDataNotFound{req.symbol, "ticks"}
+	}
+
+	page, next, err := s.tickPage(req.symbol, req.dRange, quotes, trades, cursor, limit)
+	if err != nil {
+		return err
+	}
+	if next != nil {
+		w.Header().Set(nextCursorHeader, next.String())
+	}
+
+	return writeData(w, r, req.format, len(page), func(i int) interface{} {
+		return page[i]
+	}, tickCSVHeader, func(i int) []string {
+		return tickCSVRecord(page[i])
+	})
+}
+
+func (s *HTTPServer) hasTicks(symbol string, quotes bool, trades bool) bool {
+	for _, f := range s.Storage.tickViewFolders(quotes, trades) {
+		if fileExists(path.Join(s.Storage.Path, "ticks", f, symbolStoragePath(symbol))) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reads ticks day by day starting at cursor until page is full. Returns cursor of the next page or nil if range is
+// read completely
+func (s *HTTPServer) tickPage(symbol string, dRange DateRange, quotes bool, trades bool, cursor *tickCursor,
+	limit int) (TickArray, *tickCursor, error) {
+
+	var page TickArray
+	for day := cursor.day; !day.After(dRange.To); day = day.AddDate(0, 0, 1) {
+		ticks, err := s.Storage.getStoredTickView(symbol, DateRange{day, day}, quotes, trades)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		skip := 0
+		if day.Equal(cursor.day) {
+			skip = cursor.index
+		}
+		if skip > len(ticks) {
+			skip = len(ticks)
+		}
+		ticks = ticks[skip:]
+
+		room := limit - len(page)
+		if len(ticks) > room {
+			page = append(page, ticks[:room]...)
+			return page, &tickCursor{day, skip + room}, nil
+		}
+		page = append(page, ticks...)
+
+		if len(page) == limit && day.Before(dRange.To) {
+			return page, &tickCursor{day.AddDate(0, 0, 1), 0}, nil
+		}
+	}
+	return page, nil, nil
+}
+
+func (s *HTTPServer) handleCoverage(w http.ResponseWriter, r *http.Request) error {
+	symbol := r.URL.Query().Get("symbol")
+	if symbol == "" {
+		return badRequest("symbol is required")
+	}
+	coverage, err := s.Storage.Coverage(symbol)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, coverage)
+}
+
+type updateResponse struct {
+	Symbol string
+	Status string // "updated" or "up to date"
+}
+
+func updateResult(w http.ResponseWriter, symbol string, err error) error {
+	if _, ok := errors.Cause(err).(*ErrNothingToDownload); ok {
+		return writeJSON(w, http.StatusOK, updateResponse{symbol, "up to date"})
+	}
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, updateResponse{symbol, "updated"})
+}
+
+func (s *HTTPServer) handleUpdateCandles(w http.ResponseWriter, r *http.Request) error {
+	req, err := parseDataRequest(r)
+	if err != nil {
+		return err
+	}
+	tf := r.URL.Query().Get("timeframe")
+	if tf == "" {
+		tf = "D"
+	}
+
+	params := CandlesUpdateParams{Symbol: req.symbol, TimeFrame: tf, FromDate: req.dRange.From, ToDate: req.dRange.To}
+	return updateResult(w, req.symbol, s.Storage.UpdateSymbolCandles(params))
+}
+
+func (s *HTTPServer) handleUpdateTicks(w http.ResponseWriter, r *http.Request) error {
+	req, err := parseDataRequest(r)
+	if err != nil {
+		return err
+	}
+	quotes, err := boolParam(r, "quotes", true)
+	if err != nil {
+		return err
+	}
+	trades, err := boolParam(r, "trades", true)
+	if err != nil {
+		return err
+	}
+
+	// Whole days are updated
+	params := TickUpdateParams{Symbol: req.symbol, FromDate: req.dRange.From, ToDate: req.dRange.To, Quotes: quotes,
+		Trades: trades, StartTime: TimeOfDay{}, EndTime: TimeOfDay{23, 59, 59}}
+	switch r.URL.Query().Get("mode") {
+	case "", "fail_fast":
+	case "best_effort":
+		params.Mode = BestEffortUpdate
+	default:
+		return badRequest("mode should be fail_fast or best_effort")
+	}
+
+	err = params.checkErrors()
+	if err != nil {
+		return &httpError{http.StatusBadRequest, err}
+	}
+	return updateResult(w, req.symbol, s.Storage.UpdateSymbolTicksContext(r.Context(), params))
+}
+
+// Writes n items as json array, csv or ndjson. Body is gzipped if client accepts it
+func writeData(w http.ResponseWriter, r *http.Request, format string, n int, item func(i int) interface{},
+	csvHeader []string, csvRecord func(i int) []string) error {
+
+	var out io.Writer = w
+	if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Add("Vary", "Accept-Encoding")
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		out = gz
+	}
+
+	flush := func() {
+		if gz, ok := out.(*gzip.Writer); ok {
+			gz.Flush()
+		}
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		cw := csv.NewWriter(out)
+		cw.Write(csvHeader)
+		for i := 0; i < n; i++ {
+			// Client has gone, nothing To report
+			if cw.Write(csvRecord(i)) != nil {
+				return nil
+			}
+		}
+		cw.Flush()
+		return nil
+
+	case "ndjson":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(out)
+		for i := 0; i < n; i++ {
+			// Client has gone, nothing To report
+			if enc.Encode(item(i)) != nil {
+				return nil
+			}
+			if (i+1)%ndjsonFlushEvery == 0 {
+				flush()
+			}
+		}
+		return nil
+
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		items := make([]interface{}, n)
+		for i := range items {
+			items[i] = item(i)
+		}
+		json.NewEncoder(out).Encode(items)
+		return nil
+	}
+}
+
+var candleCSVHeader = []string{"Symbol", "Datetime", "Open", "High", "Low", "Close", "AdjClose", "Volume",
+	"OpenInterest"}
+
+func candleCSVRecord(c *Candle) []string {
+	return []string{
+		c.Symbol,
+		c.Datetime.Format(time.RFC3339Nano),
+		formatCSVFloat(c.Open),
+		formatCSVFloat(c.High),
+		formatCSVFloat(c.Low),
+		formatCSVFloat(c.Close),
+		formatCSVFloat(c.AdjClose),
+		strconv.FormatInt(c.Volume, 10),
+		strconv.FormatInt(c.OpenInterest, 10),
+	}
+}
+
+var tickCSVHeader = []string{"Symbol", "Datetime", "Seq", "LastPrice", "LastSize", "LastExch", "BidPrice",
+	"AskPrice", "BidSize", "AskSize", "BidExch", "AskExch", "CondQuote", "Cond1", "Cond2", "Cond3", "Cond4"}
+
+func tickCSVRecord(t *Tick) []string {
+	return []string{
+		t.Symbol,
+		t.Datetime.Format(time.RFC3339Nano),
+		strconv.FormatInt(t.Seq, 10),
+		formatCSVFloat(t.LastPrice),
+		strconv.FormatInt(t.LastSize, 10),
+		t.LastExch,
+		formatCSVFloat(t.BidPrice),
+		formatCSVFloat(t.AskPrice),
+		strconv.FormatInt(t.BidSize, 10),
+		strconv.FormatInt(t.AskSize, 10),
+		t.BidExch,
+		t.AskExch,
+		t.CondQuote,
+		t.Cond1,
+		t.Cond2,
+		t.Cond3,
+		t.Cond4,
+	}
+}
+
+func formatCSVFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,167 @@
+package marketdata
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPServer(t *testing.T) {
+	testDir := "./test_data/http_server"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC, Market: WeekdaysMarket, Provider: &quotesTradesMock{}}
+	_, err := storage.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	candles := CandleArray{
+		&Candle{Symbol: "SPY", Close: 270, Volume: 100, Datetime: timeOnTheFly(2018, 11, 1)},
+		&Candle{Symbol: "SPY", Close: 271, Volume: 200, Datetime: timeOnTheFly(2018, 11, 2)},
+		&Candle{Symbol: "SPY", Close: 272, Volume: 300, Datetime: timeOnTheFly(2018, 11, 5)},
+	}
+	err = storage.saveCandlesToFile(&candles, path.Join(testDir, "candles/day/SPY.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(NewHTTPServer(&storage))
+	defer server.Close()
+
+	get := func(query string) (*http.Response, string) {
+		resp, err := http.Get(server.URL + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	resp, err := http.Post(server.URL+"/update/ticks?symbol=SPY&from=2018-11-01&to=2018-11-02", "", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Candles
+	resp, body := get("/candles?symbol=SPY&from=2018-11-02&to=2018-11-05")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var loaded CandleArray
+	assert.Nil(t, json.Unmarshal([]byte(body), &loaded))
+	assert.Equal(t, 2, len(loaded))
+
+	_, body = get("/candles?symbol=SPY&from=2018-11-01&to=2018-11-01&format=csv")
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.Equal(t, "SPY,2018-11-01T00:00:00Z,0,0,0,270,0,100,0", lines[1])
+
+	// Ticks by pages
+	resp, body = get("/ticks?symbol=SPY&from=2018-11-01&to=2018-11-02&limit=3&format=ndjson")
+	assert.Equal(t, 3, len(strings.Split(strings.TrimSpace(body), "\n")))
+	cursor := resp.Header.Get(nextCursorHeader)
+	assert.Equal(t, "2018-11-02.1", cursor)
+
+	resp, body = get("/ticks?symbol=SPY&from=2018-11-01&to=2018-11-02&limit=3&cursor=" + cursor)
+	var ticks TickArray
+	assert.Nil(t, json.Unmarshal([]byte(body), &ticks))
+	assert.Equal(t, 1, len(ticks))
+	assert.Equal(t, "", resp.Header.Get(nextCursorHeader))
+
+	_, body = get("/ticks?symbol=SPY&from=2018-11-01&to=2018-11-02&quotes=false")
+	ticks = nil
+	assert.Nil(t, json.Unmarshal([]byte(body), &ticks))
+	assert.Equal(t, 2, len(ticks))
+
+	// Gzip
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/ticks?symbol=SPY&from=2018-11-01&to=2018-11-02", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err = http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	gz, err := gzip.NewReader(resp.Body)
+	assert.Nil(t, err)
+	ticks = nil
+	assert.Nil(t, json.NewDecoder(gz).Decode(&ticks))
+	assert.Equal(t, 4, len(ticks))
+	resp.Body.Close()
+
+	// Coverage
+	_, body = get("/coverage?symbol=SPY")
+	var coverage SymbolCoverage
+	assert.Nil(t, json.Unmarshal([]byte(body), &coverage))
+	assert.Equal(t, 2, coverage.Datasets["ticks/quotes_trades"].Days)
+	assert.Equal(t, 1, len(coverage.Datasets["ticks/quotes_trades"].Ranges))
+
+	// Errors
+	resp, _ = get("/ticks?symbol=QQQ&from=2018-11-01&to=2018-11-02")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = get("/ticks?symbol=SPY&from=2018-11&to=2018-11-02")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, _ = get("/update/ticks?symbol=SPY&from=2018-11-01&to=2018-11-02")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+// Returns only ticks of quotesTradesMock which are in requested range
+type rangeTicksMock struct {
+	quotesTradesMock
+}
+
+func (p *rangeTicksMock) GetTicks(symbol string, dRange DateRange, quotes bool, trades bool) (TickArray, error) {
+	ticks, err := p.quotesTradesMock.GetTicks(symbol, dRange, quotes, trades)
+	var inRange TickArray
+	for _, t := range ticks {
+		if !t.Datetime.Before(dRange.From) && !t.Datetime.After(dRange.To) {
+			inRange = append(inRange, t)
+		}
+	}
+	return inRange, err
+}
+
+func TestHTTPServer_UpdateTicks(t *testing.T) {
+	testDir := "./test_data/http_server_update"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC, Market: WeekdaysMarket, Provider: &rangeTicksMock{}}
+	server := httptest.NewServer(NewHTTPServer(&storage))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/update/ticks?symbol=SPY&from=2018-11-01&to=2018-11-01", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Stored day has ticks of the whole day, not an empty file
+	day := timeOnTheFly(2018, 11, 1)
+	stored, err := storage.readTicksFromFile(path.Join(storage.symbolTicksFolder("SPY", true, true),
+		day.Format(tickfilelayout)+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(*stored))
+}
+
+func TestListedRanges(t *testing.T) {
+	dates := []time.Time{timeOnTheFly(2018, 11, 5), timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 2),
+		timeOnTheFly(2018, 11, 7)}
+
+	ranges := listedRanges(dates, WeekdaysMarket)
+	assert.Equal(t, []DateRange{{timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 5)},
+		{timeOnTheFly(2018, 11, 7), timeOnTheFly(2018, 11, 7)}}, ranges)
+
+	ranges = listedRanges(dates, AllDaysMarket)
+	assert.Equal(t, 3, len(ranges))
+}
@@ -0,0 +1,201 @@
+package marketdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	instrumentsFileName = "instruments.json"
+
+	// Alias key of ActiveTick vendor symbols in Instrument.Aliases
+	ActiveTickVendor = "ActiveTick"
+)
+
+type AssetClass string
+
+const (
+	Stock  AssetClass = "Stock"
+	ETF    AssetClass = "ETF"
+	Index  AssetClass = "Index"
+	Option AssetClass = "Option"
+	Future AssetClass = "Future"
+	Crypto AssetClass = "Crypto"
+	Forex  AssetClass = "Forex"
+)
+
+type ErrInstrumentNotFound struct {
+	symbol string
+}
+
+func (e *ErrInstrumentNotFound) Error() string {
+	return fmt.Sprintf("Instrument %v not found in registry", e.symbol)
+}
+
+// Instrument reference data. Symbol is our canonical symbol, Aliases map provider name To vendor symbol.
+// Zero ListingDate or DelistingDate means the date is unknown and range isn't limited From this side.
+type Instrument struct {
+	Symbol        string
+	Exchange      string
+	AssetClass    AssetClass
+	Currency      string
+	TickSize      float64
+	LotSize       int64
+	ListingDate   time.Time
+	DelistingDate time.Time
+	Aliases       map[string]string
+}
+
+// Returns vendor symbol of instrument or canonical symbol if there is no alias for this vendor
+func (i *Instrument) VendorSymbol(vendor string) string {
+	if alias, ok := i.Aliases[vendor]; ok && alias != "" {
+		return alias
+	}
+	return i.Symbol
+}
+
+func (i *Instrument) IsListed(d time.Time) bool {
+	if !i.ListingDate.IsZero() && setTimeToSOD(d).Before(setTimeToSOD(i.ListingDate)) {
+		return false
+	}
+	if !i.DelistingDate.IsZero() && setTimeToSOD(d).After(setTimeToSOD(i.DelistingDate)) {
+		return false
+	}
+	return true
+}
+
+// Cuts range To dates when instrument was traded. Returns false if there is nothing left
+func (i *Instrument) clipRange(rng DateRange) (DateRange, bool) {
+	if !i.ListingDate.IsZero() {
+		listing := time.Date(i.ListingDate.Year(), i.ListingDate.Month(), i.ListingDate.Day(), 0, 0, 0, 0,
+			rng.From.Location())
+		if rng.From.Before(listing) {
+			rng.From = listing
+		}
+	}
+
+	if !i.DelistingDate.IsZero() {
+		delisting := time.Date(i.DelistingDate.Year(), i.DelistingDate.Month(), i.DelistingDate.Day(), 0, 0, 0, 0,
+			rng.To.Location())
+		if rng.To.After(delisting) {
+			rng.To = delisting
+		}
+	}
+
+	if rng.From.After(rng.To) {
+		return rng, false
+	}
+	return rng, true
+}
+
+// Symbol master. Keeps instruments by canonical symbol and persists them To single json file.
+type InstrumentRegistry struct {
+	Path string
+
+	instruments map[string]*Instrument
+	mu          sync.RWMutex
+}
+
+func NewInstrumentRegistry(pth string) *InstrumentRegistry {
+	return &InstrumentRegistry{
+		Path:        pth,
+		instruments: make(map[string]*Instrument),
+	}
+}
+
+// Loads registry From file. Missing file gives empty registry
+func LoadInstrumentRegistry(pth string) (*InstrumentRegistry, error) {
+	r := NewInstrumentRegistry(pth)
+	if !fileExists(pth) {
+		return r, nil
+	}
+
+	byteValue, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return nil, err
+	}
+
+	var instruments []*Instrument
+	err = json.Unmarshal(byteValue, &instruments)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range instruments {
+		r.instruments[i.Symbol] = i
+	}
+
+	return r, nil
+}
+
+func (r *InstrumentRegistry) Save() error {
+	err := createDirIfNotExists(filepath.Dir(r.Path))
+	if err != nil {
+		return err
+	}
+
+	json_, err := json.MarshalIndent(r.All(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(r.Path, json_, 0644)
+}
+
+func (r *InstrumentRegistry) Add(i Instrument) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.instruments[i.Symbol] = &i
+}
+
+func (r *InstrumentRegistry) Remove(symbol string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.instruments, symbol)
+}
+
+func (r *InstrumentRegistry) Get(symbol string) (*Instrument, error) {
+	if r == nil {
+		return nil, &ErrInstrumentNotFound{symbol}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i, ok := r.instruments[symbol]
+	if !ok {
+		return nil, &ErrInstrumentNotFound{symbol}
+	}
+	return i, nil
+}
+
+// Returns all instruments sorted by symbol
+func (r *InstrumentRegistry) All() []*Instrument {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var instruments []*Instrument
+	for _, i := range r.instruments {
+		instruments = append(instruments, i)
+	}
+
+	sort.Slice(instruments, func(i, j int) bool {
+		return instruments[i].Symbol < instruments[j].Symbol
+	})
+
+	return instruments
+}
+
+// Returns vendor symbol for canonical one. Unknown symbols are returned as is
+func (r *InstrumentRegistry) VendorSymbol(symbol string, vendor string) string {
+	i, err := r.Get(symbol)
+	if err != nil {
+		return symbol
+	}
+	return i.VendorSymbol(vendor)
+}
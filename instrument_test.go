@@ -0,0 +1,75 @@
+package marketdata
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstrumentRegistry_SaveLoad(t *testing.T) {
+	pth := "./test_data/instruments_test/instruments.json"
+	defer os.RemoveAll("./test_data/instruments_test")
+
+	registry := NewInstrumentRegistry(pth)
+	registry.Add(Instrument{
+		Symbol:      "SPX",
+		Exchange:    "CBOE",
+		AssetClass:  Index,
+		Currency:    "USD",
+		TickSize:    0.01,
+		LotSize:     1,
+		ListingDate: timeOnTheFly(1957, 3, 4),
+		Aliases:     map[string]string{ActiveTickVendor: "$SPX"},
+	})
+	registry.Add(Instrument{Symbol: "SPY", AssetClass: ETF})
+
+	err := registry.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadInstrumentRegistry(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 2, len(loaded.All()))
+	assert.Equal(t, "$SPX", loaded.VendorSymbol("SPX", ActiveTickVendor))
+	assert.Equal(t, "SPY", loaded.VendorSymbol("SPY", ActiveTickVendor))
+	assert.Equal(t, "QQQ", loaded.VendorSymbol("QQQ", ActiveTickVendor))
+
+	_, err = loaded.Get("QQQ")
+	assert.IsType(t, &ErrInstrumentNotFound{}, err)
+}
+
+func TestInstrument_clipRange(t *testing.T) {
+	instrument := Instrument{
+		Symbol:        "TEST",
+		ListingDate:   timeOnTheFly(2010, 1, 5),
+		DelistingDate: timeOnTheFly(2010, 2, 1),
+	}
+
+	rng, ok := instrument.clipRange(DateRange{timeOnTheFly(2009, 1, 1), timeOnTheFly(2011, 1, 1)})
+	assert.True(t, ok)
+	assert.Equal(t, DateRange{timeOnTheFly(2010, 1, 5), timeOnTheFly(2010, 2, 1)}, rng)
+
+	_, ok = instrument.clipRange(DateRange{timeOnTheFly(2011, 1, 1), timeOnTheFly(2011, 2, 1)})
+	assert.False(t, ok)
+
+	assert.False(t, instrument.IsListed(time.Date(2010, 1, 4, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, instrument.IsListed(time.Date(2010, 2, 1, 23, 0, 0, 0, time.UTC)))
+}
+
+func TestActiveTick_vendorSymbol(t *testing.T) {
+	at := NewActiveTick(5000, "127.0.0.1", 2, "$")
+	assert.Equal(t, "$SPX", at.vendorSymbol("SPX"))
+
+	registry := NewInstrumentRegistry("")
+	registry.Add(Instrument{Symbol: "SPY", Aliases: map[string]string{ActiveTickVendor: "SPY"}})
+	at.SetInstruments(registry)
+
+	assert.Equal(t, "SPY", at.vendorSymbol("SPY"))
+	assert.Equal(t, "$SPX", at.vendorSymbol("SPX"))
+}
@@ -0,0 +1,290 @@
+package marketdata
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type IteratorOptions struct {
+	Prefetch int            // Days loaded ahead on a goroutine. 0 means days are loaded on demand
+	DayStart time.Duration  // Time of day filter: data before DayStart since midnight is skipped
+	DayEnd   time.Duration  // Data at or after DayEnd since midnight is skipped. 0 means the end of day
+	Location *time.Location // Time zone of time of day filter. Datetime location is used if nil
+}
+
+func (o *IteratorOptions) inDay(t time.Time) bool {
+	if o.DayStart == 0 && o.DayEnd == 0 {
+		return true
+	}
+	if o.Location != nil {
+		t = t.In(o.Location)
+	}
+	sinceMidnight := t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()))
+	if sinceMidnight < o.DayStart {
+		return false
+	}
+	return o.DayEnd == 0 || sinceMidnight < o.DayEnd
+}
+
+type tickDay struct {
+	day   time.Time
+	ticks TickArray
+	found bool // False if day isn't stored
+	err   error
+}
+
+// Streams stored ticks of symbol day by day, so arbitrarily long range is read in constant memory. Only one day
+// (plus prefetched days) is held at a time:
+//
+//	it := NewTickIterator(storage, "SPY", rng, false, true, IteratorOptions{Prefetch: 2})
+//	defer it.Close()
+//	for it.Next() {
+//		t := it.Tick()
+//		...
+//	}
+//	err := it.Err()
+//
+// Ticks of a day are ordered by Datetime and Seq.
+type TickIterator struct {
+	Storage Storage
+	Symbol  string
+	Range   DateRange
+	Quotes  bool
+	Trades  bool
+	Options IteratorOptions
+
+	day     time.Time // The next day To load when there is no prefetch
+	ticks   TickArray
+	pos     int
+	tick    *Tick
+	err     error
+	days    chan tickDay
+	done    chan struct{}
+	missing []time.Time
+	started bool
+	closed  bool
+}
+
+func NewTickIterator(storage Storage, symbol string, dRange DateRange, quotes bool, trades bool,
+	options IteratorOptions) *TickIterator {
+
+	return &TickIterator{
+		Storage: storage,
+		Symbol:  symbol,
+		Range:   dRange,
+		Quotes:  quotes,
+		Trades:  trades,
+		Options: options,
+		day:     dRange.From,
+	}
+}
+
+func (it *TickIterator) Next() bool {
+	if it.closed || it.err != nil {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		if it.Options.Prefetch > 0 {
+			it.days = make(chan tickDay, it.Options.Prefetch)
+			it.done = make(chan struct{})
+			go it.prefetch()
+		}
+	}
+
+	for {
+		for it.pos < len(it.ticks) {
+			t := it.ticks[it.pos]
+			it.ticks[it.pos] = nil // Passed ticks can be collected before the day is over
+			it.pos++
+			if it.Options.inDay(t.Datetime) {
+				it.tick = t
+				return true
+			}
+		}
+
+		ticks, ok, err := it.nextDay()
+		if err != nil {
+			it.err = errors.Wrapf(err, "iterate ticks of Symbol: %v", it.Symbol)
+			return false
+		}
+		if !ok {
+			it.tick = nil
+			return false
+		}
+		it.ticks, it.pos = ticks, 0
+	}
+}
+
+// Current tick. It's valid after Next returned true
+func (it *TickIterator) Tick() *Tick {
+	return it.tick
+}
+
+func (it *TickIterator) Err() error {
+	return it.err
+}
+
+// Trading days which aren't stored. Days are added as iteration passes them. Missing days are reported only for
+// JsonStorage
+func (it *TickIterator) MissingDays() []time.Time {
+	return it.missing
+}
+
+// Stops prefetching. Should be called if iteration is stopped before Next returned false
+func (it *TickIterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	it.ticks, it.tick = nil, nil
+	if it.done != nil {
+		close(it.done)
+	}
+	return nil
+}
+
+func (it *TickIterator) nextDay() (TickArray, bool, error) {
+	var day tickDay
+	if it.days == nil {
+		if it.day.After(it.Range.To) {
+			return nil, false, nil
+		}
+		day = it.loadDay(it.day)
+		it.day = it.day.AddDate(0, 0, 1)
+	} else {
+		var ok bool
+		day, ok = <-it.days
+		if !ok {
+			return nil, false, nil
+		}
+	}
+
+	if day.err == nil && !day.found {
+		it.missing = append(it.missing, day.day)
+	}
+	return day.ticks, true, day.err
+}
+
+// JsonStorage day is read directly, so missing day is known. Other storages are read with GetStoredTicks
+func (it *TickIterator) loadDay(day time.Time) tickDay {
+	var ticks TickArray
+	var err error
+	found := true
+	if storage, ok := it.Storage.(*JsonStorage); ok {
+		ticks, found, err = storage.readTickDay(it.Symbol, day, it.Quotes, it.Trades)
+	} else {
+		ticks, err = it.Storage.GetStoredTicks(it.Symbol, DateRange{day, day}, it.Quotes, it.Trades)
+	}
+	if err != nil {
+		return tickDay{day: day, err: err}
+	}
+	ticks.Sort()
+	return tickDay{day, ticks, found, nil}
+}
+
+// Loads days ahead until range is over, load fails or iterator is closed
+func (it *TickIterator) prefetch() {
+	defer close(it.days)
+	for day := it.Range.From; !day.After(it.Range.To); day = day.AddDate(0, 0, 1) {
+		loaded := it.loadDay(day)
+		select {
+		case it.days <- loaded:
+		case <-it.done:
+			return
+		}
+		if loaded.err != nil {
+			return
+		}
+	}
+}
+
+// Iterates stored candles of symbol in range ordered by Datetime. Candles of symbol are stored in one file, so
+// they are read on the first Next. Time of day filter is applied To intraday timeframes only.
+type CandleIterator struct {
+	Storage   Storage
+	Symbol    string
+	TimeFrame string
+	Range     DateRange
+	Options   IteratorOptions
+
+	candles CandleArray
+	candle  *Candle
+	err     error
+	loaded  bool
+	closed  bool
+}
+
+func NewCandleIterator(storage Storage, symbol string, timeframe string, dRange DateRange,
+	options IteratorOptions) *CandleIterator {
+
+	return &CandleIterator{
+		Storage:   storage,
+		Symbol:    symbol,
+		TimeFrame: timeframe,
+		Range:     dRange,
+		Options:   options,
+	}
+}
+
+func (it *CandleIterator) Next() bool {
+	if it.closed || it.err != nil {
+		return false
+	}
+	if !it.loaded {
+		it.loaded = true
+		it.err = it.load()
+		if it.err != nil {
+			return false
+		}
+	}
+	if len(it.candles) == 0 {
+		it.candle = nil
+		return false
+	}
+	it.candle = it.candles[0]
+	it.candles[0] = nil
+	it.candles = it.candles[1:]
+	return true
+}
+
+func (it *CandleIterator) load() error {
+	candles, err := it.Storage.GetStoredCandles(it.Symbol, it.TimeFrame, it.Range)
+	if err != nil {
+		return errors.Wrapf(err, "iterate candles of Symbol: %v", it.Symbol)
+	}
+
+	// Range end is a date, so candles of the whole last day are included
+	end := it.Range.To.AddDate(0, 0, 1)
+	intraday := it.TimeFrame != "D" && it.TimeFrame != "W"
+	for _, c := range candles {
+		if c.Datetime.Before(it.Range.From) || !c.Datetime.Before(end) {
+			continue
+		}
+		if intraday && !it.Options.inDay(c.Datetime) {
+			continue
+		}
+		it.candles = append(it.candles, c)
+	}
+	sort.SliceStable(it.candles, func(i, j int) bool {
+		return it.candles[i].Datetime.Before(it.candles[j].Datetime)
+	})
+	return nil
+}
+
+// Current candle. It's valid after Next returned true
+func (it *CandleIterator) Candle() *Candle {
+	return it.candle
+}
+
+func (it *CandleIterator) Err() error {
+	return it.err
+}
+
+func (it *CandleIterator) Close() error {
+	it.closed = true
+	it.candles, it.candle = nil, nil
+	return nil
+}
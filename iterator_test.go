@@ -0,0 +1,126 @@
+package marketdata
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTickIterator_JsonStorage(t *testing.T) {
+	testDir := "./test_data/tick_iterator"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC, Market: WeekdaysMarket, Provider: &quotesTradesMock{}}
+	params := TickUpdateParams{Symbol: "SPY", FromDate: timeOnTheFly(2018, 11, 1), ToDate: timeOnTheFly(2018, 11, 5),
+		Quotes: true, Trades: true}
+	err := storage.UpdateSymbolTicks(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dRange := DateRange{timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 5)}
+	it := NewTickIterator(&storage, "SPY", dRange, false, true, IteratorOptions{})
+	var days []int
+	for it.Next() {
+		assert.True(t, it.Tick().HasTrade())
+		days = append(days, it.Tick().Datetime.Day())
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, []int{1, 2, 5}, days)
+
+	// Quote at 10:00 is filtered out by time of day
+	it = NewTickIterator(&storage, "SPY", dRange, true, true, IteratorOptions{Prefetch: 2,
+		DayStart: 10*time.Hour + 30*time.Second, DayEnd: 16 * time.Hour})
+	count := 0
+	for it.Next() {
+		assert.True(t, it.Tick().HasTrade())
+		count++
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, 3, count)
+	assert.Empty(t, it.MissingDays())
+	it.Close()
+
+	// Days which aren't stored are reported by iterator. Weekend isn't missing
+	dRange.To = timeOnTheFly(2018, 11, 7)
+	for _, prefetch := range []int{0, 2} {
+		it = NewTickIterator(&storage, "SPY", dRange, true, true, IteratorOptions{Prefetch: prefetch})
+		count = 0
+		for it.Next() {
+			count++
+		}
+		assert.Nil(t, it.Err())
+		assert.Equal(t, 6, count)
+		assert.Equal(t, []time.Time{timeOnTheFly(2018, 11, 6), timeOnTheFly(2018, 11, 7)}, it.MissingDays())
+		it.Close()
+	}
+
+	it = NewTickIterator(&storage, "QQQ", dRange, false, true, IteratorOptions{})
+	assert.False(t, it.Next())
+	assert.NotNil(t, it.Err())
+}
+
+func TestTickIterator_Prefetch(t *testing.T) {
+	storage := &replayStorageMock{ticks: map[string]TickArray{
+		"SPY": {symbolTrade("SPY", 0, 60, 11), symbolTrade("SPY", 0, 0, 10), symbolTrade("SPY", 1, 0, 12),
+			symbolTrade("SPY", 3, 0, 13)},
+	}}
+	dRange := DateRange{timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 4)}
+
+	// Days are loaded on demand without prefetch
+	it := NewTickIterator(storage, "SPY", dRange, false, true, IteratorOptions{})
+	assert.True(t, it.Next())
+	assert.Equal(t, 1, len(storage.requested))
+	assert.Equal(t, 10.0, it.Tick().LastPrice)
+	it.Close()
+	assert.False(t, it.Next())
+
+	it = NewTickIterator(storage, "SPY", dRange, false, true, IteratorOptions{Prefetch: 1})
+	var prices []float64
+	for it.Next() {
+		prices = append(prices, it.Tick().LastPrice)
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, []float64{10, 11, 12, 13}, prices)
+	it.Close()
+
+	// Prefetching goroutine is stopped by Close
+	it = NewTickIterator(storage, "SPY", dRange, false, true, IteratorOptions{Prefetch: 1})
+	assert.True(t, it.Next())
+	it.Close()
+	for range it.days {
+	}
+	assert.False(t, it.Next())
+}
+
+func TestCandleIterator(t *testing.T) {
+	day := timeOnTheFly(2018, 11, 1)
+	at := func(d int, h int, m int) *Candle {
+		return &Candle{Symbol: "SPY", Datetime: day.AddDate(0, 0, d).Add(time.Duration(h*60+m) * time.Minute)}
+	}
+	storage := &replayStorageMock{candles: map[string]CandleArray{
+		"SPY": {at(1, 9, 30), at(0, 16, 0), at(0, 9, 30), at(0, 9, 0), at(2, 9, 30)},
+	}}
+
+	dRange := DateRange{day, day.AddDate(0, 0, 1)}
+	it := NewCandleIterator(storage, "SPY", "1", dRange, IteratorOptions{DayStart: 9*time.Hour + 30*time.Minute,
+		DayEnd: 16 * time.Hour})
+	var got []time.Time
+	for it.Next() {
+		got = append(got, it.Candle().Datetime)
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, []time.Time{at(0, 9, 30).Datetime, at(1, 9, 30).Datetime}, got)
+
+	// Daily candles aren't filtered by time of day
+	storage.candles["SPY"] = CandleArray{at(0, 0, 0), at(1, 0, 0), at(2, 0, 0)}
+	it = NewCandleIterator(storage, "SPY", "D", dRange, IteratorOptions{DayStart: 9 * time.Hour})
+	count := 0
+	for it.Next() {
+		count++
+	}
+	assert.Equal(t, 2, count)
+}
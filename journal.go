@@ -0,0 +1,482 @@
+package marketdata
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	journalFileName  = ".journal"
+	quarantineFolder = ".quarantine"
+	metaFolder       = ".meta"
+
+	journalBegin  = "begin"
+	journalCommit = "commit"
+
+	ticksDataset   = "ticks"
+	candlesDataset = "candles"
+)
+
+// Journal is shared by all storages of the process. Other processes are excluded by file lock
+var journalMu sync.Mutex
+
+// Write-ahead journal entry. Update of data folder and its meta is started with begin entry and finished with commit
+// entry. Meta of every update which is not committed is rebuilt From data on recovery
+type journalEntry struct {
+	Id      string
+	State   string
+	Dataset string // ticks or candles
+	Symbol  string
+	Data    string // Ticks folder or candles file
+	Meta    string
+}
+
+type storageJournal struct {
+	path string
+}
+
+func (p *JsonStorage) journal() *storageJournal {
+	return &storageJournal{path.Join(p.Path, journalFileName)}
+}
+
+// Locks journal for goroutines of this process and for other processes
+func (j *storageJournal) lock() (func(), error) {
+	journalMu.Lock()
+	l, err := acquireLock(path.Join(filepath.Dir(j.path), locksFolder, "journal.lock"), true, DefaultLockTimeout)
+	if err != nil {
+		journalMu.Unlock()
+		return nil, err
+	}
+	return func() {
+		l.Unlock()
+		journalMu.Unlock()
+	}, nil
+}
+
+func (j *storageJournal) append(entry journalEntry) error {
+	unlock, err := j.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	json_, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(append(json_, '\n'))
+	if err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// Writes begin entry and returns its id
+func (j *storageJournal) begin(entry journalEntry) (string, error) {
+	entry.Id = fmt.Sprintf("%v-%v", os.Getpid(), time.Now().UnixNano())
+	entry.State = journalBegin
+	return entry.Id, j.append(entry)
+}
+
+// Writes commit entry. Journal is removed when nothing is pending, so it doesn't grow
+func (j *storageJournal) commit(id string) error {
+	err := j.append(journalEntry{Id: id, State: journalCommit})
+	if err != nil {
+		return err
+	}
+
+	unlock, err := j.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	pending, err := j.readPending()
+	if err == nil && len(pending) == 0 {
+		os.Remove(j.path)
+	}
+	return nil
+}
+
+func (j *storageJournal) pending() ([]journalEntry, error) {
+	unlock, err := j.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return j.readPending()
+}
+
+// Entries which are begun but not committed. Broken last line of interrupted append is ignored
+func (j *storageJournal) readPending() ([]journalEntry, error) {
+	f, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var begun []journalEntry
+	committed := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry journalEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			continue
+		}
+		switch entry.State {
+		case journalBegin:
+			begun = append(begun, entry)
+		case journalCommit:
+			committed[entry.Id] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var pending []journalEntry
+	for _, e := range begun {
+		if _, ok := committed[e.Id]; !ok {
+			pending = append(pending, e)
+		}
+	}
+	return pending, nil
+}
+
+// Removes entries with given ids. Committed entries are dropped too, so only pending ones are left
+func (j *storageJournal) remove(ids []string) error {
+	unlock, err := j.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	pending, err := j.readPending()
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	for _, e := range pending {
+		if containsString(ids, e.Id) {
+			continue
+		}
+		json_, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		data = append(data, append(json_, '\n')...)
+	}
+
+	if len(data) == 0 {
+		err = os.Remove(j.path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return writeFileAtomic(j.path, data, 0644)
+}
+
+type RecoveryReport struct {
+	RemovedTempFiles []string
+	Quarantined      []string // Corrupt files moved To .quarantine with the same relative path
+	RebuiltMeta      []string
+}
+
+// Creates storage folders, checks storage manifest and repairs storage after crash. Should be called before updates.
+// Storage with old layout or with different settings isn't opened
+func (p *JsonStorage) Open() (*RecoveryReport, error) {
+	err := checkMarketMode(p.Market, p.HasWeekends)
+	if err != nil {
+		return nil, err
+	}
+	err = p.createFolders()
+	if err != nil {
+		return nil, err
+	}
+	err = p.checkManifest()
+	if err != nil {
+		return nil, err
+	}
+	return p.Recover(false)
+}
+
+// Removes temp files of interrupted writes, quarantines corrupt files and rebuilds meta of interrupted updates.
+// Only folders of interrupted updates are checked unless fullScan is set. Symbols which are being updated by other
+// process right now are skipped.
+func (p *JsonStorage) Recover(fullScan bool) (*RecoveryReport, error) {
+	report := RecoveryReport{}
+
+	pending, err := p.journal().pending()
+	if err != nil {
+		return nil, err
+	}
+
+	locks := recoveryLocks{p, make(map[string]*storageLock)}
+	defer locks.unlockAll()
+
+	targets := make(map[string]journalEntry)
+	var processed []string
+	var roots []string
+	for _, e := range pending {
+		ok, err := locks.lock(e.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		targets[e.Meta] = e
+		processed = append(processed, e.Id)
+		roots = append(roots, e.Data, e.Meta)
+	}
+
+	if fullScan {
+		roots = []string{path.Join(p.Path, ticksDataset), path.Join(p.Path, candlesDataset)}
+	}
+
+	for _, root := range roots {
+		err = p.recoverFiles(root, &report, targets, &locks)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var metas []string
+	for m := range targets {
+		metas = append(metas, m)
+	}
+	sort.Strings(metas)
+
+	for _, m := range metas {
+		err = p.rebuildMetaLocked(targets[m])
+		if err != nil {
+			return nil, err
+		}
+		report.RebuiltMeta = append(report.RebuiltMeta, m)
+	}
+
+	return &report, p.journal().remove(processed)
+}
+
+// Update locks taken by recovery. Every symbol is locked once, lock of symbol which is being updated is not waited
+type recoveryLocks struct {
+	storage *JsonStorage
+	locks   map[string]*storageLock
+}
+
+// Returns false if symbol is locked by running update
+func (r *recoveryLocks) lock(symbol string) (bool, error) {
+	if l, ok := r.locks[symbol]; ok {
+		return l != nil, nil
+	}
+
+	l, err := acquireLock(r.storage.lockPath(symbol, "update"), true, 0)
+	if _, busy := err.(*ErrLockTimeout); busy {
+		r.locks[symbol] = nil
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	r.locks[symbol] = l
+	return true, nil
+}
+
+func (r *recoveryLocks) unlockAll() {
+	for _, l := range r.locks {
+		l.Unlock()
+	}
+}
+
+func (p *JsonStorage) recoverFiles(root string, report *RecoveryReport, targets map[string]journalEntry,
+	locks *recoveryLocks) error {
+
+	if !fileExists(root) {
+		return nil
+	}
+
+	return filepath.Walk(root, func(pth string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == quarantineFolder {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		temp := isTempFile(info.Name())
+		if !temp && !strings.HasSuffix(info.Name(), ".json") {
+			return nil
+		}
+
+		target, known := p.rebuildTarget(pth)
+		if known {
+			ok, err := locks.lock(target.Symbol)
+			if err != nil || !ok {
+				return err
+			}
+		}
+
+		if temp {
+			report.RemovedTempFiles = append(report.RemovedTempFiles, pth)
+			return os.Remove(pth)
+		}
+
+		if p.isValidJsonFile(pth) {
+			return nil
+		}
+
+		err = p.quarantine(pth)
+		if err != nil {
+			return err
+		}
+		report.Quarantined = append(report.Quarantined, pth)
+
+		if _, exists := targets[target.Meta]; known && !exists {
+			targets[target.Meta] = target
+		}
+		return nil
+	})
+}
+
+func (*JsonStorage) isValidJsonFile(pth string) bool {
+	f, err := os.Open(pth)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	var v interface{}
+	return json.NewDecoder(bufio.NewReader(f)).Decode(&v) == nil
+}
+
+func (p *JsonStorage) quarantine(pth string) error {
+	rel, err := filepath.Rel(p.Path, pth)
+	if err != nil {
+		return err
+	}
+	dst := path.Join(p.Path, quarantineFolder, rel)
+	err = createDirIfNotExists(filepath.Dir(dst))
+	if err != nil {
+		return err
+	}
+	return os.Rename(pth, dst)
+}
+
+// Meta which should be rebuilt after quarantine of file. Layouts are ticks/<folder>/<symbol>/<date>.json,
+// ticks/<folder>/.meta/<symbol>.json, candles/day/<symbol>.json and candles/day/.meta/<symbol>.json
+func (p *JsonStorage) rebuildTarget(pth string) (journalEntry, bool) {
+	rel, err := filepath.Rel(p.Path, pth)
+	if err != nil {
+		return journalEntry{}, false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) < 3 {
+		return journalEntry{}, false
+	}
+
+	// Temp file belongs To the file it replaces
+	if name := parts[len(parts)-1]; isTempFile(name) {
+		parts[len(parts)-1] = name[1:strings.Index(name, tempFileSuffix)]
+	}
+
+	dataset, folder := parts[0], parts[1]
+	if dataset == candlesDataset && folder != "day" {
+		return journalEntry{}, false
+	}
+
+	var symbol string
+	switch {
+	case parts[2] == metaFolder:
+		symbol = strings.TrimSuffix(path.Join(parts[3:]...), ".json")
+	case dataset == ticksDataset && len(parts) > 3:
+		symbol = path.Join(parts[2 : len(parts)-1]...)
+	case dataset == candlesDataset:
+		symbol = strings.TrimSuffix(path.Join(parts[2:]...), ".json")
+	default:
+		return journalEntry{}, false
+	}
+	if strings.HasPrefix(symbol, ".") {
+		return journalEntry{}, false
+	}
+
+	entry := journalEntry{
+		Dataset: dataset,
+		Symbol:  path.Base(symbol),
+		Meta:    path.Join(p.Path, dataset, folder, metaFolder, symbol+".json"),
+	}
+	if dataset == ticksDataset {
+		entry.Data = path.Join(p.Path, dataset, folder, symbol)
+	} else {
+		entry.Data = path.Join(p.Path, dataset, folder, symbol+".json")
+	}
+	return entry, true
+}
+
+func (p *JsonStorage) rebuildMetaLocked(e journalEntry) error {
+	dataLock, err := p.lockSymbolData(e.Symbol, true)
+	if err != nil {
+		return err
+	}
+	defer dataLock.Unlock()
+
+	return p.rebuildMeta(e)
+}
+
+// Meta is derived From data: tick meta lists stored days, candles meta lists range of stored candles
+func (p *JsonStorage) rebuildMeta(e journalEntry) error {
+	switch e.Dataset {
+	case ticksDataset:
+		meta := loadMetaIfExists(e.Meta)
+		meta.HasWeekends = p.HasWeekends
+		meta.Market = p.Market
+		meta.ListedDates = nil
+		if fileExists(e.Data) {
+			listed, err := p.getStoredTickDates(e.Data)
+			if err != nil {
+				return err
+			}
+			meta.ListedDates = p.completedDates(listed)
+		}
+		return meta.save(e.Meta)
+
+	case candlesDataset:
+		candles, err := p.readCandlesFromFile(e.Data)
+		if err != nil || len(candles) == 0 {
+			// Without candles meta is removed, so everything is downloaded again
+			err = os.Remove(e.Meta)
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		sorted := sortedCopy(candles)
+		rng := DateRange{sorted[0].Datetime, sorted[len(sorted)-1].Datetime}
+		return p.genNewDailySymbolMeta(e.Symbol, &rng).save(e.Meta)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package marketdata
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	testDir := "./test_data/atomic"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	pth := path.Join(testDir, "a/b.json")
+	err := writeFileAtomic(pth, []byte("[1]"), 0644)
+	assert.Nil(t, err)
+	err = writeFileAtomic(pth, []byte("[2]"), 0644)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile(pth)
+	assert.Nil(t, err)
+	assert.Equal(t, "[2]", string(data))
+
+	files, err := ioutil.ReadDir(path.Join(testDir, "a"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(files))
+}
+
+func TestStorageJournal(t *testing.T) {
+	testDir := "./test_data/journal"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	j := &storageJournal{path.Join(testDir, journalFileName)}
+	id1, err := j.begin(journalEntry{Dataset: ticksDataset, Symbol: "SPY"})
+	assert.Nil(t, err)
+	_, err = j.begin(journalEntry{Dataset: ticksDataset, Symbol: "QQQ"})
+	assert.Nil(t, err)
+	assert.Nil(t, j.commit(id1))
+
+	// Interrupted append
+	f, _ := os.OpenFile(j.path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"Id":"1-2","Sta`)
+	f.Close()
+
+	pending, err := j.pending()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pending))
+	assert.Equal(t, "QQQ", pending[0].Symbol)
+}
+
+func TestJsonStorage_Recover(t *testing.T) {
+	testDir := "./test_data/recover"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC, Market: WeekdaysMarket}
+	if err := storage.newManifest().save(storage.manifestPath()); err != nil {
+		t.Fatal(err)
+	}
+	folder := path.Join(testDir, "ticks/quotes_trades/SPY")
+	metaPath := path.Join(testDir, "ticks/quotes_trades/.meta/SPY.json")
+
+	ticks := TickArray{tradeAt(0, 10)}
+	for _, d := range []string{"2018-11-01", "2018-11-02"} {
+		err := storage.saveTicksToFile(&ticks, path.Join(folder, d+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Process died during update: truncated day, temp file and stale meta
+	ioutil.WriteFile(path.Join(folder, "2018-11-05.json"), []byte(`[{"Symbol":"SP`), 0644)
+	ioutil.WriteFile(path.Join(folder, ".2018-11-06.json.tmp123"), []byte(`[`), 0644)
+	meta := JsonSymbolMeta{Symbol: "SPY", ListedDates: []time.Time{timeOnTheFly(2018, 11, 1)}}
+	meta.save(metaPath)
+	_, err := storage.journal().begin(journalEntry{Dataset: ticksDataset, Symbol: "SPY", Data: folder,
+		Meta: metaPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := storage.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(report.RemovedTempFiles))
+	assert.Equal(t, []string{path.Join(folder, "2018-11-05.json")}, report.Quarantined)
+	assert.Equal(t, []string{metaPath}, report.RebuiltMeta)
+	assert.True(t, fileExists(path.Join(testDir, quarantineFolder, "ticks/quotes_trades/SPY/2018-11-05.json")))
+	assert.False(t, fileExists(path.Join(testDir, journalFileName)))
+
+	rebuilt := loadMetaIfExists(metaPath)
+	assert.Equal(t, 2, len(rebuilt.ListedDates))
+
+	// Full scan finds corrupt candles without journal and removes their meta
+	candlesPath := path.Join(testDir, "candles/day/SPY.json")
+	os.MkdirAll(path.Join(testDir, "candles/day"), os.ModePerm)
+	ioutil.WriteFile(candlesPath, []byte(`[{"Open":`), 0644)
+	(&JsonSymbolMeta{Symbol: "SPY"}).save(path.Join(testDir, "candles/day/.meta/SPY.json"))
+
+	report, err = storage.Recover(false)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(report.Quarantined))
+
+	report, err = storage.Recover(true)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{candlesPath}, report.Quarantined)
+	assert.False(t, fileExists(path.Join(testDir, "candles/day/.meta/SPY.json")))
+}
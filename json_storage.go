@@ -13,6 +13,7 @@ import (
 	"sync"
 	"context"
 	"strings"
+	"sort"
 )
 
 const (
@@ -35,6 +36,25 @@ func (*ErrNothingToDownload) Error() string {
 	return "Nothing To download"
 }
 
+type DayError struct {
+	Date time.Time
+	Err  error
+}
+
+// Days which failed in best-effort tick update. Other days are saved
+type ErrTickUpdateFailed struct {
+	Symbol string
+	Days   []DayError
+}
+
+func (e *ErrTickUpdateFailed) Error() string {
+	var days []string
+	for _, d := range e.Days {
+		days = append(days, fmt.Sprintf("%v: %v", d.Date.Format(tickfilelayout), d.Err))
+	}
+	return fmt.Sprintf("%v ticks update failed for %v days: %v", e.Symbol, len(e.Days), strings.Join(days, "; "))
+}
+
 type ErrSymbolDataNotFound struct {
 	symbol string
 	path   string
@@ -50,7 +70,8 @@ type JsonSymbolMeta struct {
 	Symbol      string
 	TimeFrame   string
 	ListedDates []time.Time
-	HasWeekends bool
+	HasWeekends bool       // Legacy. True means AllDaysMarket if Market is empty
+	Market      MarketMode // WeekdaysMarket if empty and HasWeekends isn't set
 }
 
 func (j *JsonSymbolMeta) Load(loadPath string) error {
@@ -74,6 +95,10 @@ func (j *JsonSymbolMeta) Load(loadPath string) error {
 
 }
 
+func (j *JsonSymbolMeta) marketMode() MarketMode {
+	return resolveMarketMode(j.Market, j.HasWeekends)
+}
+
 func (j *JsonSymbolMeta) datesSet() map[int64]struct{} {
 	// To avoid TimeZone issues we put in dateset unix times.
 	dates := make(map[int64]struct{})
@@ -107,7 +132,7 @@ func (j *JsonSymbolMeta) getEmptyRanges(rng *DateRange) ([]*DateRange, error) {
 
 	fmt.Println(emptyDates)
 	min_split := 1
-	if j.HasWeekends {
+	if j.marketMode() == WeekdaysMarket {
 		min_split = 3
 	}
 
@@ -157,7 +182,7 @@ func (j *JsonSymbolMeta) getEmptyDates(rng *DateRange) ([]time.Time, error) {
 		if last.After(rng.To) {
 			break
 		}
-		if j.HasWeekends && (last.Weekday() == 0 || last.Weekday() == 6) {
+		if !j.marketMode().isTradingDay(last) {
 			last = last.AddDate(0, 0, 1)
 			continue
 		}
@@ -217,7 +242,7 @@ func (j *JsonSymbolMeta) save(savePath string) error {
 		return err
 	}
 
-	err = ioutil.WriteFile(savePath, json_, 0644)
+	err = writeFileAtomic(savePath, json_, 0644)
 
 	return err
 }
@@ -241,7 +266,57 @@ type JsonStorage struct {
 	Path          string
 	Provider      HistoryProvider
 	TimeZone      *time.Location
-	HasWeekends   bool
+	HasWeekends   bool // Legacy. True means AllDaysMarket if Market is empty, it conflicts with other Market
+	Instruments   *InstrumentRegistry // Optional. Used To skip dates before listing and after delisting
+
+	CorporateActions *CorporateActionsStore // Optional. Splits and dividends for adjusted candles
+	AdjustedCandles  bool                   // GetStoredCandles returns back-adjusted candles if true
+
+	Market        MarketMode // WeekdaysMarket if empty and HasWeekends isn't set
+	DailyRollHour int        // UTC hour when daily candle of 24/7 market starts
+
+	ReadTickFilters  *TickFilterPipeline // Optional. Applied To ticks loaded From storage
+	WriteTickFilters *TickFilterPipeline // Optional. Applied To downloaded ticks before they are saved
+
+	LockTimeout time.Duration // How long To wait for symbol lock held by other process. 0 means DefaultLockTimeout
+}
+
+func (p *JsonStorage) marketMode() MarketMode {
+	return resolveMarketMode(p.Market, p.HasWeekends)
+}
+
+// Loads instruments registry stored in storage root
+func (p *JsonStorage) LoadInstruments() error {
+	instruments, err := LoadInstrumentRegistry(path.Join(p.Path, instrumentsFileName))
+	if err != nil {
+		return err
+	}
+	p.Instruments = instruments
+	return nil
+}
+
+// Loads corporate actions stored in storage root
+func (p *JsonStorage) LoadCorporateActions() error {
+	actions, err := LoadCorporateActions(path.Join(p.Path, corporateActionsFileName))
+	if err != nil {
+		return err
+	}
+	p.CorporateActions = actions
+	return nil
+}
+
+// Cuts range To instrument trading dates if instrument is known
+func (p *JsonStorage) clipToListing(symbol string, dRange DateRange) (DateRange, error) {
+	instrument, err := p.Instruments.Get(symbol)
+	if err != nil {
+		return dRange, nil
+	}
+
+	clipped, ok := instrument.clipRange(dRange)
+	if !ok {
+		return dRange, errors.Wrapf(&ErrNothingToDownload{}, "%v is not listed in %v", symbol, &dRange)
+	}
+	return clipped, nil
 }
 
 func (p *JsonStorage) createFolders() error {
@@ -264,10 +339,25 @@ func (p *JsonStorage) createFolders() error {
 
 func (p *JsonStorage) GetStoredCandles(symbol string, tf string, dRange DateRange) (CandleArray, error) {
 
+	err := p.ensureManifest(false)
+	if err != nil {
+		return nil, err
+	}
+
 	switch tf {
 	case "D":
-		pth := path.Join(p.Path, "candles", "day", symbol+".json")
-		return p.readCandlesFromFile(pth)
+		dataLock, err := p.lockSymbolData(symbol, false)
+		if err != nil {
+			return nil, err
+		}
+		defer dataLock.Unlock()
+
+		pth := path.Join(p.Path, "candles", "day", symbolStoragePath(symbol)+".json")
+		candles, err := p.readCandlesFromFile(pth)
+		if err != nil || !p.AdjustedCandles {
+			return candles, err
+		}
+		return AdjustCandles(candles, p.CorporateActions.Get(symbol)), nil
 	case "W":
 		return nil, errors.New("Not implemented")
 
@@ -278,14 +368,28 @@ func (p *JsonStorage) GetStoredCandles(symbol string, tf string, dRange DateRang
 }
 
 func (p *JsonStorage) GetStoredTicks(symbol string, dRange DateRange, quotes bool, trades bool) (TickArray, error) {
+	err := p.ensureManifest(false)
+	if err != nil {
+		return nil, err
+	}
 
-	symbolTickFolder := path.Join(p.Path, "ticks", p.generateTicksFolderName(quotes, trades), symbol)
+	if quotes != trades {
+		return p.getStoredTickView(symbol, dRange, quotes, trades)
+	}
+
+	symbolTickFolder := path.Join(p.Path, "ticks", p.generateTicksFolderName(quotes, trades), symbolStoragePath(symbol))
 
 	if !fileExists(symbolTickFolder) {
 
 		return nil, errors.New(symbol + " not found in storage")
 	}
 
+	dataLock, err := p.lockSymbolData(symbol, false)
+	if err != nil {
+		return nil, err
+	}
+	defer dataLock.Unlock()
+
 	start := dRange.From
 	var loaded TickArray
 
@@ -294,7 +398,7 @@ func (p *JsonStorage) GetStoredTicks(symbol string, dRange DateRange, quotes boo
 			break
 		}
 
-		if p.HasWeekends && (start.Weekday() == 6 || start.Weekday() == 0) {
+		if !p.marketMode().isTradingDay(start) {
 			start = start.AddDate(0, 0, 1)
 			continue
 		}
@@ -321,23 +425,140 @@ func (p *JsonStorage) GetStoredTicks(symbol string, dRange DateRange, quotes boo
 
 	}
 
+	if p.ReadTickFilters != nil {
+		loaded, _ = p.ReadTickFilters.Apply(symbol, loaded)
+	}
+
 	return loaded, nil
 }
 
+// Reads ticks of every day in range. For each day folders are checked in given order and the first existing file
+// is used
+func (p *JsonStorage) getStoredTickDays(symbol string, dRange DateRange, folders ...string) (TickArray, error) {
+	var found bool
+	for _, f := range folders {
+		if fileExists(path.Join(p.Path, "ticks", f, symbolStoragePath(symbol))) {
+			found = true
+		}
+	}
+	if !found {
+		return nil, errors.New(symbol + " not found in storage")
+	}
+
+	dataLock, err := p.lockSymbolData(symbol, false)
+	if err != nil {
+		return nil, err
+	}
+	defer dataLock.Unlock()
+
+	var loaded TickArray
+	for start := dRange.From; !start.After(dRange.To); start = start.AddDate(0, 0, 1) {
+		if !p.marketMode().isTradingDay(start) {
+			continue
+		}
+
+		for _, f := range folders {
+			pth := path.Join(p.Path, "ticks", f, symbolStoragePath(symbol), start.Format(tickfilelayout)+".json")
+			if !fileExists(pth) {
+				continue
+			}
+
+			ticks, err := p.readTicksFromFile(pth)
+			if err != nil {
+				return nil, errors.Wrapf(err, "getStoredTickDays() file: %v", pth)
+			}
+			loaded = append(loaded, *ticks...)
+			break
+		}
+	}
+
+	if p.ReadTickFilters != nil {
+		loaded, _ = p.ReadTickFilters.Apply(symbol, loaded)
+	}
+
+	return loaded, nil
+}
+
+// Reads ticks of one day. Folders which can serve request are checked in order. Found is false if day isn't
+// stored, non trading day is always found and empty
+func (p *JsonStorage) readTickDay(symbol string, day time.Time, quotes bool, trades bool) (TickArray, bool, error) {
+	err := p.ensureManifest(false)
+	if err != nil {
+		return nil, false, err
+	}
+
+	folders := p.tickViewFolders(quotes, trades)
+	var symbolFound bool
+	for _, f := range folders {
+		if fileExists(path.Join(p.Path, "ticks", f, symbolStoragePath(symbol))) {
+			symbolFound = true
+		}
+	}
+	if !symbolFound {
+		return nil, false, errors.New(symbol + " not found in storage")
+	}
+	if !p.marketMode().isTradingDay(day) {
+		return nil, true, nil
+	}
+
+	dataLock, err := p.lockSymbolData(symbol, false)
+	if err != nil {
+		return nil, false, err
+	}
+	defer dataLock.Unlock()
+
+	for _, f := range folders {
+		pth := path.Join(p.Path, "ticks", f, symbolStoragePath(symbol), day.Format(tickfilelayout)+".json")
+		if !fileExists(pth) {
+			continue
+		}
+
+		ticks, err := p.readTicksFromFile(pth)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "readTickDay() file: %v", pth)
+		}
+		loaded := *ticks
+		if quotes != trades {
+			loaded = loaded.filterKind(quotes, trades)
+		}
+		if p.ReadTickFilters != nil {
+			loaded, _ = p.ReadTickFilters.Apply(symbol, loaded)
+		}
+		return loaded, true, nil
+	}
+	return nil, false, nil
+}
+
 func (p *JsonStorage) UpdateSymbolCandles(params CandlesUpdateParams) error {
 	err := params.checkErrors()
 
+	if err != nil {
+		return err
+	}
+	err = p.ensureManifest(true)
 	if err != nil {
 		return err
 	}
 
 	params.modifyTimes()
 
-	dRange := DateRange{
-		params.FromDate, params.ToDate,
+	dRange, err := p.clipToListing(params.Symbol, DateRange{params.FromDate, params.ToDate})
+	if err != nil {
+		if _, ok := errors.Cause(err).(*ErrNothingToDownload); ok {
+			return nil
+		}
+		return err
 	}
+
 	switch params.TimeFrame {
 	case "D":
+		// Candle of current day isn't complete and future days have no candles, so they are never stored
+		if last := p.lastCompletedDay(); dRange.To.After(last) {
+			dRange.To = last
+		}
+		if dRange.From.After(dRange.To) {
+			return nil
+		}
 		return p.updateDailyCandles(params.Symbol, &dRange)
 	case "W":
 		return p.updateWeeklyCandles(params.Symbol, &dRange)
@@ -370,7 +591,7 @@ func (p *JsonStorage) saveCandlesToFile(candles *CandleArray, savePath string) e
 		return err
 	}
 
-	err = ioutil.WriteFile(savePath, json_, 0644)
+	err = writeFileAtomic(savePath, json_, 0644)
 	if err != nil {
 		fmt.Println(fmt.Sprintf("Can't write candles To file: %v", err))
 	}
@@ -407,7 +628,13 @@ func (*JsonStorage) readCandlesFromFile(pth string) (CandleArray, error) {
 
 func (p *JsonStorage) updateDailyCandles(s string, dRange *DateRange) error {
 
-	downloadRange, err := p.findDailyRangeToDownload(dRange, s)
+	updateLock, err := p.lockSymbolUpdate(s)
+	if err != nil {
+		return err
+	}
+	defer updateLock.Unlock()
+
+	downloadRanges, listedRange, err := p.findDailyRangeToDownload(dRange, s)
 	if err != nil {
 		switch err.(type) {
 		case *ErrNothingToDownload:
@@ -417,72 +644,137 @@ func (p *JsonStorage) updateDailyCandles(s string, dRange *DateRange) error {
 		}
 	}
 
-	candles, err1 := p.Provider.GetCandles(s, "D", *downloadRange)
-	if err1 != nil {
-		return err1
+	var downloaded CandleArray
+	for _, rng := range downloadRanges {
+		candles, err := p.Provider.GetCandles(s, "D", *rng)
+		if err != nil {
+			return err
+		}
+		downloaded = append(downloaded, candles...)
+	}
+
+	savePath := path.Join(p.Path, "candles/day", symbolStoragePath(s)+".json")
+	metaPath := path.Join(p.Path, "candles/day/.meta", symbolStoragePath(s)+".json")
+
+	journalId, err := p.journal().begin(journalEntry{Dataset: candlesDataset, Symbol: s, Data: savePath,
+		Meta: metaPath})
+	if err != nil {
+		return err
+	}
+
+	dataLock, err := p.lockSymbolData(s, true)
+	if err != nil {
+		return err
+	}
+	defer dataLock.Unlock()
+
+	var stored CandleArray
+	if fileExists(savePath) {
+		stored, err = p.readCandlesFromFile(savePath)
+		if err != nil {
+			return err
+		}
 	}
+	candles := mergeCandles(stored, downloaded)
 
-	savePath := path.Join(p.Path, "candles/day", s+".json")
 	err2 := p.saveCandlesToFile(&candles, savePath)
 
 	if err2 != nil {
 		return err2
 	}
 
-	newMeta := p.genNewDailySymbolMeta(s, downloadRange)
-	metaPath := path.Join(p.Path, "candles/day/.meta", s+".json")
+	newMeta := p.genNewDailySymbolMeta(s, listedRange)
 	err3 := newMeta.save(metaPath)
 	if err3 != nil {
 		fmt.Println(err3)
 		return err3
 	}
-	return nil
+	return p.journal().commit(journalId)
+
+}
 
+// Merges downloaded candles into stored ones. Downloaded candle replaces stored candle of the same date
+func mergeCandles(stored CandleArray, downloaded CandleArray) CandleArray {
+	merged := make(map[int64]*Candle)
+	for _, c := range stored {
+		merged[c.Datetime.Unix()] = c
+	}
+	for _, c := range downloaded {
+		merged[c.Datetime.Unix()] = c
+	}
+
+	candles := make(CandleArray, 0, len(merged))
+	for _, c := range merged {
+		candles = append(candles, c)
+	}
+	candles.Sort()
+	return candles
 }
 
-func (p *JsonStorage) findDailyRangeToDownload(dRange *DateRange, symbol string) (*DateRange, error) {
-	metaPath := path.Join(p.Path, "candles/day/.meta", symbol+".json")
+// Returns ranges missing in storage and the whole range listed after they are downloaded. Listed range is kept
+// contiguous, so gap between listed dates and requested range is downloaded too
+func (p *JsonStorage) findDailyRangeToDownload(dRange *DateRange, symbol string) ([]*DateRange, *DateRange, error) {
+	metaPath := path.Join(p.Path, "candles/day/.meta", symbolStoragePath(symbol)+".json")
 	downloadRange := *dRange
 
 	if !fileExists(metaPath) {
-		return &downloadRange, nil
+		return []*DateRange{&downloadRange}, &downloadRange, nil
 	}
 
 	symbolMeta := JsonSymbolMeta{}
 	err := symbolMeta.Load(metaPath)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	firstListed, ok1 := symbolMeta.firstDate()
 	lastListed, ok2 := symbolMeta.lastDate()
 
-	if !ok1 && !ok2 {
-		return &downloadRange, nil
+	if !ok1 || !ok2 {
+		return []*DateRange{&downloadRange}, &downloadRange, nil
 	}
 
-	if firstListed.Unix() < dRange.From.Unix() && lastListed.Unix() > dRange.To.Unix() && ok1 && ok2 {
-		// If we already have all candles in this date range just return without errors
-		return nil, &ErrNothingToDownload{}
+	var missing []*DateRange
+	listed := DateRange{firstListed, lastListed}
+
+	before := DateRange{dRange.From, firstListed.AddDate(0, 0, -1)}
+	if !before.From.After(before.To) {
+		missing = append(missing, &before)
+		listed.From = dRange.From
 	}
 
-	if dRange.From.Unix() > firstListed.Unix() && ok1 {
-		downloadRange.From = firstListed
+	after := DateRange{lastListed.AddDate(0, 0, 1), dRange.To}
+	if !after.From.After(after.To) {
+		missing = append(missing, &after)
+		listed.To = dRange.To
 	}
 
-	if dRange.To.Unix() < lastListed.Unix() && ok2 {
-		downloadRange.To = lastListed
+	if len(missing) == 0 {
+		// If we already have all candles in this date range just return without errors
+		return nil, nil, &ErrNothingToDownload{}
 	}
 
-	return &downloadRange, nil
+	return missing, &listed, nil
 
 }
 
+// Yesterday in storage TimeZone as daily candle date (UTC midnight)
+func (p *JsonStorage) lastCompletedDay() time.Time {
+	loc := p.TimeZone
+	if loc == nil {
+		loc = time.UTC
+	}
+	now := time.Now().In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+}
+
+// Lists days of range. Current and future days are never listed
 func (p *JsonStorage) genNewDailySymbolMeta(symbol string, dateRange *DateRange) *JsonSymbolMeta {
 	var listedDates []time.Time
+	last := p.lastCompletedDay()
 	lastD := dateRange.From
 	for {
-		if lastD.After(dateRange.To) {
+		if lastD.After(dateRange.To) || lastD.After(last) {
 			break
 		}
 		listedDates = append(listedDates, lastD)
@@ -495,6 +787,7 @@ func (p *JsonStorage) genNewDailySymbolMeta(symbol string, dateRange *DateRange)
 		"D",
 		listedDates,
 		p.HasWeekends,
+		p.Market,
 	}
 
 	return &symbolMeta
@@ -515,23 +808,48 @@ set. For example if end date is 2012-01-12 9:45AM we download full day of 2012-0
 and update everything until yesterday. Today is always ignored. We use TimeZone To check this
 */
 func (p *JsonStorage) UpdateSymbolTicks(params TickUpdateParams) error {
+	return p.UpdateSymbolTicksContext(context.Background(), params)
+}
+
+// The same as UpdateSymbolTicks but update stops when context is cancelled. Days which are already downloaded stay
+// in storage
+func (p *JsonStorage) UpdateSymbolTicksContext(ctx context.Context, params TickUpdateParams) error {
 	err := params.checkErrors()
 	if err != nil {
 		return err
 	}
+	err = p.ensureManifest(true)
+	if err != nil {
+		return err
+	}
 
-	err = params.modifyTimes(p.TimeZone)
+	if p.marketMode() == AllDaysMarket {
+		// Current day is updated up To the last completed hour, but it's never listed in meta
+		err = params.modifyTimesUntil(p.TimeZone, setTimeToSOD(time.Now().In(p.TimeZone)))
+	} else {
+		err = params.modifyTimes(p.TimeZone)
+	}
 	if err != nil {
 		return err
 	}
 
 	folderName := p.generateTicksFolderName(params.Quotes, params.Trades)
-	metaPath := path.Join(p.Path, "ticks", folderName, ".meta", params.Symbol+".json")
+	metaPath := path.Join(p.Path, "ticks", folderName, ".meta", symbolStoragePath(params.Symbol)+".json")
+
+	updateLock, err := p.lockSymbolUpdate(params.Symbol)
+	if err != nil {
+		return err
+	}
+	defer updateLock.Unlock()
 
 	jsonMeta := loadMetaIfExists(metaPath)
 	jsonMeta.HasWeekends = p.HasWeekends
+	jsonMeta.Market = p.Market
 
-	dRange := DateRange{params.FromDate, params.ToDate}
+	dRange, err := p.clipToListing(params.Symbol, DateRange{params.FromDate, params.ToDate})
+	if err != nil {
+		return err
+	}
 
 	emptyDates, err := jsonMeta.getEmptyDates(&dRange)
 
@@ -539,58 +857,70 @@ func (p *JsonStorage) UpdateSymbolTicks(params TickUpdateParams) error {
 		return err
 	}
 
+	emptyDates = p.withoutCombinedDates(params.Symbol, params.Quotes, params.Trades, emptyDates)
+
 	if emptyDates == nil {
 		return errors.Wrapf(&ErrNothingToDownload{}, "UpdateSymbolTicks() Symbol: %v dRange: %v", params.Symbol, &dRange)
 	}
 
-	wg := &sync.WaitGroup{}
+	storageFolder := path.Join(p.Path, "ticks", folderName, symbolStoragePath(params.Symbol))
 
-	datesChan := make(chan tickRequestParams, 2)
-	errorsChan := make(chan error)
-	successChan := make(chan struct{}, 1)
-	ctx, finish := context.WithCancel(context.Background())
+	// Meta is rebuilt by recovery if process dies before commit
+	journalId, err := p.journal().begin(journalEntry{Dataset: ticksDataset, Symbol: params.Symbol,
+		Data: storageFolder, Meta: metaPath})
+	if err != nil {
+		return err
+	}
 
 	defer func() {
-		if errorsChan != nil {
-			//close(errorsChan)
-			errorsChan = nil
-		}
-		if successChan != nil {
-			//close(successChan)
-			successChan = nil
-		}
-
-		finish()
-
-		storageFolder := path.Join(p.Path, "ticks", folderName, params.Symbol)
 		listedDates, err := p.getStoredTickDates(storageFolder)
 		if err != nil {
 			//Todo log here?
 			return
 		}
-		jsonMeta.ListedDates = listedDates
-		jsonMeta.save(metaPath)
+		jsonMeta.ListedDates = p.completedDates(listedDates)
+
+		dataLock, err := p.lockSymbolData(params.Symbol, true)
+		if err != nil {
+			return
+		}
+		defer dataLock.Unlock()
+		if jsonMeta.save(metaPath) == nil {
+			p.journal().commit(journalId)
+		}
 
 	}()
 
-	var retError error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := p.UpdateWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(emptyDates) {
+		workers = len(emptyDates)
+	}
+
+	jobs := make(chan tickRequestParams)
+	results := make(chan tickDayResult)
+	wg := &sync.WaitGroup{}
 
 	//Workers pool
-	for i := 0; i < p.UpdateWorkers; i++ {
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
 		go func() {
-			wg.Add(1)
-			p.tickUpdateWorker(ctx, datesChan, errorsChan, successChan)
-			fmt.Println("Done")
-			wg.Done()
+			defer wg.Done()
+			p.tickUpdateWorker(ctx, jobs, results)
 		}()
 	}
 
-	// Requests producer
+	// Requests producer. It stops on cancellation, so workers don't get new days after the first error in
+	// fail-fast mode
 	go func() {
-		defer close(datesChan)
+		defer close(jobs)
 		for _, d := range emptyDates {
-
-			params := tickRequestParams{
+			par := tickRequestParams{
 				params.Trades,
 				params.Quotes,
 				d,
@@ -600,100 +930,157 @@ func (p *JsonStorage) UpdateSymbolTicks(params TickUpdateParams) error {
 				params.EndTime,
 			}
 
-			datesChan <- params
+			select {
+			case jobs <- par:
+			case <-ctx.Done():
+				return
+			}
 		}
-
 	}()
 
-	counter := 0
-
-LoadingLoop:
-	for {
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
 
-		select {
-		case err, ok := <-errorsChan:
-			if !ok {
-				continue LoadingLoop
-			}
-			errorsChan = nil
-			datesChan = nil
-			finish()
-			return err
-		case <-successChan:
-			counter++
-			fmt.Println(counter, len(emptyDates))
-			if counter == len(emptyDates) {
-				finish()
-				break LoadingLoop
+	progress := UpdateProgress{Symbol: params.Symbol, Total: len(emptyDates)}
+	started := time.Now()
+	var failed []DayError
+
+	for res := range results {
+		progress.Done++
+		progress.Bytes += res.bytes
+		progress.Elapsed = time.Since(started)
+		progress.ETA = progress.Elapsed / time.Duration(progress.Done) * time.Duration(progress.Total-progress.Done)
+
+		if res.err != nil {
+			progress.Failed++
+			failed = append(failed, DayError{res.date, res.err})
+			if params.Mode != BestEffortUpdate {
+				cancel()
 			}
+		}
 
-		default:
-			if counter == len(emptyDates) {
-				finish()
-				break LoadingLoop
-			}
+		if params.Progress != nil {
+			params.Progress(progress)
+		}
+	}
 
+	if len(failed) > 0 {
+		if params.Mode != BestEffortUpdate {
+			return errors.Wrapf(failed[0].Err, "UpdateSymbolTicks() Symbol: %v Date: %v", params.Symbol,
+				failed[0].Date.Format(tickfilelayout))
 		}
+		sort.Slice(failed, func(i, j int) bool {
+			return failed[i].Date.Before(failed[j].Date)
+		})
+		return &ErrTickUpdateFailed{params.Symbol, failed}
 	}
 
-	wg.Wait()
+	if progress.Done < progress.Total {
+		return errors.Wrapf(ctx.Err(), "UpdateSymbolTicks() Symbol: %v", params.Symbol)
+	}
 
-	return retError
+	return nil
 }
 
-func (p *JsonStorage) tickUpdateWorker(ctx context.Context, params chan tickRequestParams, errorsChan chan<- error,
-	successChan chan<- struct{}) {
-LOOP:
-	for {
-		select {
-		case <-ctx.Done():
+type tickDayResult struct {
+	date  time.Time
+	bytes int64
+	err   error
+}
+
+func (p *JsonStorage) tickUpdateWorker(ctx context.Context, jobs <-chan tickRequestParams,
+	results chan<- tickDayResult) {
+
+	for par := range jobs {
+		if ctx.Err() != nil {
 			return
-		case par, ok := <-params:
+		}
+		bytes, err := p.updateTickDay(par)
+		results <- tickDayResult{par.date, bytes, err}
+	}
+}
 
-			if !ok {
-				return
-			}
+// Downloads and saves ticks of single day. Returns size of saved file
+func (p *JsonStorage) updateTickDay(par tickRequestParams) (int64, error) {
+	d := par.date
+	r := DateRange{}
+	r.From = time.Date(d.Year(), d.Month(), d.Day(), par.startTime.Hour, par.startTime.Minute, par.startTime.Second, 0, time.UTC)
+	r.To = time.Date(d.Year(), d.Month(), d.Day(), par.endTime.Hour, par.endTime.Minute, par.endTime.Second, 0, time.UTC)
 
-			d := par.date
-			r := DateRange{}
-			r.From = time.Date(d.Year(), d.Month(), d.Day(), par.startTime.Hour, par.startTime.Minute, par.startTime.Second, 0, time.UTC)
-			r.To = time.Date(d.Year(), d.Month(), d.Day(), par.endTime.Hour, par.endTime.Minute, par.endTime.Second, 0, time.UTC)
+	if p.marketMode() == AllDaysMarket {
+		var ok bool
+		r, ok = p.cutToCompletedHour(r)
+		if !ok {
+			return 0, nil
+		}
+	}
 
-			folderName := p.generateTicksFolderName(par.quotes, par.trades)
+	folderName := p.generateTicksFolderName(par.quotes, par.trades)
 
-			savePath := path.Join(p.Path, "ticks", folderName, par.symbol, par.date.Format(tickfilelayout)+".json")
+	savePath := path.Join(p.Path, "ticks", folderName, symbolStoragePath(par.symbol), par.date.Format(tickfilelayout)+".json")
 
-			ticks, err := p.Provider.GetTicks(par.symbol, r, par.quotes, par.trades)
-			if err != nil {
-				switch errors.Cause(err).(type) {
-				case *ErrEmptyResponse:
-					err = p.saveTicksToFile(&ticks, savePath)
-					if err != nil {
-						errorsChan <- err
-						return
-					}
-					successChan <- struct{}{}
-					continue LOOP
-
-				default:
-					errorsChan <- err
-					return
-
-				}
-			}
+	ticks, err := p.Provider.GetTicks(par.symbol, r, par.quotes, par.trades)
+	if err != nil {
+		switch errors.Cause(err).(type) {
+		case *ErrEmptyResponse:
+			ticks = nil
+		default:
+			return 0, err
+		}
+	}
 
-			err = p.saveTicksToFile(&ticks, savePath)
+	if p.WriteTickFilters != nil && len(ticks) > 0 {
+		ticks, _ = p.WriteTickFilters.Apply(par.symbol, ticks)
+	}
 
-			if err != nil {
-				errorsChan <- err
-				return
-			}
+	dataLock, err := p.lockSymbolData(par.symbol, true)
+	if err != nil {
+		return 0, err
+	}
+	err = p.saveTicksToFile(&ticks, savePath)
+	dataLock.Unlock()
+	if err != nil {
+		return 0, err
+	}
 
-			successChan <- struct{}{}
+	info, err := os.Stat(savePath)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
 
-		}
+// Current day of 24/7 market can be requested only up To the last completed hour. Range uses wall clock of
+// storage TimeZone in UTC as all tick requests do. Returns false if there is nothing completed yet.
+func (p *JsonStorage) cutToCompletedHour(r DateRange) (DateRange, bool) {
+	last := lastCompletedHour(time.Now().In(p.TimeZone))
+	limit := time.Date(last.Year(), last.Month(), last.Day(), last.Hour(), 0, 0, 0, time.UTC).Add(-time.Second)
 
+	if r.To.After(limit) {
+		r.To = limit
+	}
+	if r.To.Before(r.From) {
+		return r, false
+	}
+	return r, true
+}
+
+// Current day of 24/7 market is stored partially, so it's never listed in meta and will be updated next time
+func (p *JsonStorage) completedDates(dates []time.Time) []time.Time {
+	if p.marketMode() != AllDaysMarket {
+		return dates
+	}
+
+	today := setTimeToSOD(time.Now().In(p.TimeZone))
+	var completed []time.Time
+	for _, d := range dates {
+		if d.Before(today) {
+			completed = append(completed, d)
+		}
 	}
+	return completed
 }
 
 func (*JsonStorage) generateTicksFolderName(quotes bool, trades bool) string {
@@ -724,7 +1111,7 @@ func (p *JsonStorage) saveTicksToFile(ticks *TickArray, savePath string) error {
 		return err
 	}
 
-	err = ioutil.WriteFile(savePath, json_, 0644)
+	err = writeFileAtomic(savePath, json_, 0644)
 	if err != nil {
 		fmt.Println(fmt.Sprintf("Can't write ticks To file: %v", err))
 	}
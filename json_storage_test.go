@@ -9,6 +9,8 @@ import (
 	"path"
 	"io/ioutil"
 	"sort"
+	"context"
+	"github.com/pkg/errors"
 )
 
 func getSymbolMetaMock() *JsonSymbolMeta {
@@ -31,6 +33,7 @@ func getSymbolMetaMock() *JsonSymbolMeta {
 		"D",
 		storedDates,
 		true,
+		"",
 	}
 
 	symbMeta.save("test_data/daily_ranges/candles/day/.meta/TEST.json")
@@ -59,7 +62,7 @@ func TestJsonSymbolMeta_getEmptyDates(t *testing.T) {
 	}
 
 	jsonMeta = JsonSymbolMeta{}
-	jsonMeta.HasWeekends = true
+	jsonMeta.Market = WeekdaysMarket
 
 	listedDates := []time.Time{
 		timeOnTheFly(2018, 11, 28),
@@ -92,7 +95,8 @@ func TestJsonSymbolMeta_getEmptyDates(t *testing.T) {
 		assert.Equal(t, v, expecting[i])
 	}
 
-	jsonMeta.HasWeekends = false
+	jsonMeta.Market = ""
+	jsonMeta.HasWeekends = true
 
 	emptyDates, err = jsonMeta.getEmptyDates(&rng)
 	if err != nil {
@@ -213,6 +217,14 @@ func TestJsonStorage_findDailyRangeToDownload(t *testing.T) {
 		at,
 		loc,
 		true,
+		nil,
+		nil,
+		false,
+		"",
+		0,
+		nil,
+		nil,
+		0,
 	}
 
 	getSymbolMetaMock()
@@ -221,52 +233,48 @@ func TestJsonStorage_findDailyRangeToDownload(t *testing.T) {
 		timeOnTheFly(2010, 5, 30),
 	}
 
-	actual1, err := storage.findDailyRangeToDownload(&range1, testSymbol)
+	actual1, listed1, err := storage.findDailyRangeToDownload(&range1, testSymbol)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	expected1 := DateRange{
-		timeOnTheFly(2010, 1, 1),
-		timeOnTheFly(2010, 5, 30),
-	}
-
-	assert.Equal(t, expected1, *actual1)
+	assert.Equal(t, []*DateRange{{timeOnTheFly(2010, 1, 31), timeOnTheFly(2010, 5, 30)}}, actual1)
+	assert.Equal(t, DateRange{timeOnTheFly(2010, 1, 1), timeOnTheFly(2010, 5, 30)}, *listed1)
 
 	range2 := DateRange{
 		timeOnTheFly(2009, 1, 30),
 		timeOnTheFly(2009, 5, 30),
 	}
 
-	actual2, err := storage.findDailyRangeToDownload(&range2, testSymbol)
+	// Gap between requested and listed range is downloaded too
+	actual2, listed2, err := storage.findDailyRangeToDownload(&range2, testSymbol)
 	if err != nil {
 		t.Fatal(err)
 	}
-	expected2 := DateRange{
-		timeOnTheFly(2009, 1, 30),
-		timeOnTheFly(2010, 1, 30),
-	}
-
-	assert.Equal(t, expected2, *actual2)
+	assert.Equal(t, []*DateRange{{timeOnTheFly(2009, 1, 30), timeOnTheFly(2009, 12, 31)}}, actual2)
+	assert.Equal(t, DateRange{timeOnTheFly(2009, 1, 30), timeOnTheFly(2010, 1, 30)}, *listed2)
 
 	range3 := DateRange{
-		timeOnTheFly(2010, 1, 15),
-		timeOnTheFly(2010, 1, 20),
-	}
-
-	_, err = storage.findDailyRangeToDownload(&range3, testSymbol)
-	if err == nil {
-		t.Fatal("should be error: ErrNothingToDownload")
-	} else {
-		switch err.(type) {
-		case *ErrNothingToDownload:
-			fmt.Println("Got expected error. OK!")
-			return
-		default:
-			t.Fatal("should be error: ErrNothingToDownload")
-		}
+		timeOnTheFly(2009, 12, 1),
+		timeOnTheFly(2010, 2, 5),
 	}
+	actual3, _, err := storage.findDailyRangeToDownload(&range3, testSymbol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []*DateRange{
+		{timeOnTheFly(2009, 12, 1), timeOnTheFly(2009, 12, 31)},
+		{timeOnTheFly(2010, 1, 31), timeOnTheFly(2010, 2, 5)},
+	}, actual3)
 
+	// Listed bounds are included
+	for _, rng := range []DateRange{
+		{timeOnTheFly(2010, 1, 15), timeOnTheFly(2010, 1, 20)},
+		{timeOnTheFly(2010, 1, 1), timeOnTheFly(2010, 1, 30)},
+	} {
+		_, _, err = storage.findDailyRangeToDownload(&rng, testSymbol)
+		assert.IsType(t, &ErrNothingToDownload{}, err)
+	}
 }
 
 func TestJsonStorage_ensureFolder(t *testing.T) {
@@ -281,6 +289,14 @@ func TestJsonStorage_ensureFolder(t *testing.T) {
 		mockActiveTick(),
 		loc,
 		true,
+		nil,
+		nil,
+		false,
+		"",
+		0,
+		nil,
+		nil,
+		0,
 	}
 
 	err = s.createFolders()
@@ -305,7 +321,8 @@ func TestJsonStorage_readCandlesFromFile(t *testing.T) {
 func TestJsonStorage_saveCandlesToFile(t *testing.T) {
 	defer os.Remove("./test_data/save_test.json")
 
-	at := mockActiveTick()
+	at, server := fakeActiveTick()
+	defer server.Close()
 	dRange := DateRange{timeOnTheFly(2010, 1, 1),
 		timeOnTheFly(2012, 5, 3)}
 	candles, err := at.GetCandles("SPY", "D", dRange)
@@ -323,7 +340,15 @@ func TestJsonStorage_saveCandlesToFile(t *testing.T) {
 		"./test_data",
 		at,
 		loc,
-		true,
+		false,
+		nil,
+		nil,
+		false,
+		WeekdaysMarket,
+		0,
+		nil,
+		nil,
+		0,
 	}
 
 	err = storage.saveCandlesToFile(&candles, "./test_data/save_test.json")
@@ -337,7 +362,8 @@ func TestJsonStorage_saveCandlesToFile(t *testing.T) {
 func TestJsonStorage_saveAndLoadCandles(t *testing.T) {
 	defer os.Remove("./test_data/TEST_read_write.json")
 
-	at := mockActiveTick()
+	at, server := fakeActiveTick()
+	defer server.Close()
 	dRange := DateRange{timeOnTheFly(2010, 1, 1),
 		timeOnTheFly(2012, 5, 3)}
 	candles, err := at.GetCandles("SPY", "D", dRange)
@@ -355,7 +381,15 @@ func TestJsonStorage_saveAndLoadCandles(t *testing.T) {
 		"./test_data",
 		at,
 		loc,
-		true,
+		false,
+		nil,
+		nil,
+		false,
+		WeekdaysMarket,
+		0,
+		nil,
+		nil,
+		0,
 	}
 
 	err = storage.saveCandlesToFile(&candles, "./test_data/TEST_read_write.json")
@@ -379,7 +413,8 @@ func TestJsonStorage_updateDailyCandles(t *testing.T) {
 	testDir := "./test_data/json_storage"
 	os.RemoveAll(testDir)
 
-	at := mockActiveTick()
+	at, server := fakeActiveTick()
+	defer server.Close()
 	createDirIfNotExists(testDir)
 
 	loc, err := time.LoadLocation("America/New_York")
@@ -392,7 +427,15 @@ func TestJsonStorage_updateDailyCandles(t *testing.T) {
 		testDir,
 		at,
 		loc,
-		true,
+		false,
+		nil,
+		nil,
+		false,
+		WeekdaysMarket,
+		0,
+		nil,
+		nil,
+		0,
 	}
 
 	//storage.createFolders()
@@ -436,7 +479,8 @@ func TestJsonStorage_updateTicks(t *testing.T) {
 	testDir := "./test_data/json_storage"
 	os.RemoveAll(testDir)
 
-	at := mockActiveTick()
+	at, server := fakeActiveTick()
+	defer server.Close()
 	createDirIfNotExists(testDir)
 
 	loc, err := time.LoadLocation("America/New_York")
@@ -452,7 +496,15 @@ func TestJsonStorage_updateTicks(t *testing.T) {
 		testDir,
 		at,
 		loc,
-		true,
+		false,
+		nil,
+		nil,
+		false,
+		WeekdaysMarket,
+		0,
+		nil,
+		nil,
+		0,
 	}
 
 	start := timeOnTheFly(2018, 10, 1)
@@ -466,10 +518,11 @@ func TestJsonStorage_updateTicks(t *testing.T) {
 		endTime,
 		true,
 		true,
+		FailFastUpdate,
+		nil,
 	}
 
 	err = storage.UpdateSymbolTicks(params)
-
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -510,6 +563,8 @@ func TestJsonStorage_updateTicks(t *testing.T) {
 		endTime,
 		true,
 		true,
+		FailFastUpdate,
+		nil,
 	}
 
 	err = storage.UpdateSymbolTicks(params)
@@ -590,7 +645,8 @@ func TestJsonStorage_GetStoredTicks(t *testing.T) {
 	testDir := "./test_data/json_storage"
 	os.RemoveAll(testDir)
 
-	at := mockActiveTick()
+	at, server := fakeActiveTick()
+	defer server.Close()
 	createDirIfNotExists(testDir)
 
 	loc, err := time.LoadLocation("America/New_York")
@@ -606,7 +662,15 @@ func TestJsonStorage_GetStoredTicks(t *testing.T) {
 		testDir,
 		at,
 		loc,
-		true,
+		false,
+		nil,
+		nil,
+		false,
+		WeekdaysMarket,
+		0,
+		nil,
+		nil,
+		0,
 	}
 
 	start := timeOnTheFly(2018, 10, 1)
@@ -620,10 +684,11 @@ func TestJsonStorage_GetStoredTicks(t *testing.T) {
 		endTime,
 		true,
 		true,
+		FailFastUpdate,
+		nil,
 	}
 
 	err = storage.UpdateSymbolTicks(params)
-
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -683,3 +748,75 @@ func TestJsonStorage_GetStoredTicks(t *testing.T) {
 
 	}
 }
+
+// Fails on given weekday, other days are served by dailyTicksMock
+type failingTicksMock struct {
+	dailyTicksMock
+	failOn time.Weekday
+}
+
+func (p *failingTicksMock) GetTicks(symbol string, dRange DateRange, quotes bool, trades bool) (TickArray, error) {
+	if dRange.From.Weekday() == p.failOn {
+		return nil, &ErrUnexpectedResponseCode{500, "mock"}
+	}
+	return p.dailyTicksMock.GetTicks(symbol, dRange, quotes, trades)
+}
+
+func TestJsonStorage_UpdateSymbolTicksModes(t *testing.T) {
+	testDir := "./test_data/update_modes"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	provider := &failingTicksMock{failOn: time.Wednesday}
+	storage := JsonStorage{UpdateWorkers: 3, Path: testDir, Provider: provider, TimeZone: time.UTC,
+		Market: WeekdaysMarket}
+
+	var progress []UpdateProgress
+	// Two weeks: 10 trading days, 2 of them are Wednesdays
+	params := TickUpdateParams{
+		Symbol:    "SPY",
+		FromDate:  timeOnTheFly(2018, 11, 5),
+		ToDate:    timeOnTheFly(2018, 11, 16),
+		StartTime: TimeOfDay{9, 30, 0},
+		EndTime:   TimeOfDay{16, 0, 0},
+		Quotes:    true,
+		Trades:    true,
+		Mode:      BestEffortUpdate,
+		Progress: func(p UpdateProgress) {
+			progress = append(progress, p)
+		},
+	}
+
+	err := storage.UpdateSymbolTicks(params)
+	failed, ok := err.(*ErrTickUpdateFailed)
+	assert.True(t, ok, fmt.Sprint(err))
+	assert.Equal(t, 2, len(failed.Days))
+	assert.Equal(t, timeOnTheFly(2018, 11, 7), failed.Days[0].Date)
+
+	assert.Equal(t, 10, len(progress))
+	last := progress[len(progress)-1]
+	assert.Equal(t, 10, last.Done)
+	assert.Equal(t, 2, last.Failed)
+	assert.Equal(t, 10, last.Total)
+	assert.True(t, last.Bytes > 0)
+	assert.Equal(t, time.Duration(0), last.ETA)
+
+	stored, err := storage.getStoredTickDates(path.Join(testDir, "ticks/quotes_trades/SPY"))
+	assert.Nil(t, err)
+	assert.Equal(t, 8, len(stored))
+
+	// Failed days are the only ones left. Fail-fast returns the first error
+	params.Mode = FailFastUpdate
+	params.Progress = nil
+	err = storage.UpdateSymbolTicks(params)
+	assert.IsType(t, &ErrUnexpectedResponseCode{}, errors.Cause(err))
+
+	// Cancelled update doesn't request anything
+	provider.failOn = time.Sunday
+	provider.requested = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = storage.UpdateSymbolTicksContext(ctx, params)
+	assert.Equal(t, context.Canceled, errors.Cause(err))
+	assert.Equal(t, 0, len(provider.requested))
+}
@@ -0,0 +1,94 @@
+package marketdata
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+const (
+	locksFolder        = ".locks"
+	DefaultLockTimeout = 30 * time.Second
+	lockRetryInterval  = 10 * time.Millisecond
+)
+
+type ErrLockTimeout struct {
+	path      string
+	exclusive bool
+	timeout   time.Duration
+}
+
+func (e *ErrLockTimeout) Error() string {
+	mode := "shared"
+	if e.exclusive {
+		mode = "exclusive"
+	}
+	return fmt.Sprintf("Can't acquire %v lock %v in %v", mode, e.path, e.timeout)
+}
+
+// Advisory file lock. It works between processes and between goroutines of one process
+type storageLock struct {
+	f *os.File
+}
+
+func (l *storageLock) Unlock() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	defer l.f.Close()
+	return funlock(l.f)
+}
+
+// Takes lock on file. If timeout is 0 lock is tried only once
+func acquireLock(pth string, exclusive bool, timeout time.Duration) (*storageLock, error) {
+	err := createDirIfNotExists(filepath.Dir(pth))
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(pth, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := tryFlock(f, exclusive)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if ok {
+			return &storageLock{f}, nil
+		}
+		if !time.Now().Before(deadline) {
+			f.Close()
+			return nil, &ErrLockTimeout{pth, exclusive, timeout}
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+func (p *JsonStorage) lockTimeout() time.Duration {
+	if p.LockTimeout == 0 {
+		return DefaultLockTimeout
+	}
+	return p.LockTimeout
+}
+
+func (p *JsonStorage) lockPath(symbol string, kind string) string {
+	return path.Join(p.Path, locksFolder, symbolStoragePath(symbol)+"."+kind+".lock")
+}
+
+// Exclusive lock held during the whole update of symbol. Only one process updates symbol at a time
+func (p *JsonStorage) lockSymbolUpdate(symbol string) (*storageLock, error) {
+	return acquireLock(p.lockPath(symbol, "update"), true, p.lockTimeout())
+}
+
+// Lock of symbol files. Writers take it exclusively for every file and meta write, readers take it shared, so
+// reader never sees data and meta of different updates
+func (p *JsonStorage) lockSymbolData(symbol string, exclusive bool) (*storageLock, error) {
+	return acquireLock(p.lockPath(symbol, "data"), exclusive, p.lockTimeout())
+}
@@ -0,0 +1,91 @@
+package marketdata
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquireLock(t *testing.T) {
+	testDir := "./test_data/locks"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	pth := path.Join(testDir, "SPY.data.lock")
+
+	shared1, err := acquireLock(pth, false, 0)
+	assert.Nil(t, err)
+	shared2, err := acquireLock(pth, false, 0)
+	assert.Nil(t, err)
+
+	_, err = acquireLock(pth, true, 30*time.Millisecond)
+	assert.IsType(t, &ErrLockTimeout{}, err)
+
+	shared1.Unlock()
+	shared2.Unlock()
+
+	exclusive, err := acquireLock(pth, true, 0)
+	assert.Nil(t, err)
+
+	// Waiting reader gets lock when writer releases it
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		exclusive.Unlock()
+	}()
+	shared, err := acquireLock(pth, false, time.Second)
+	assert.Nil(t, err)
+	shared.Unlock()
+}
+
+func TestJsonStorage_SymbolLocks(t *testing.T) {
+	testDir := "./test_data/symbol_locks"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC, Provider: &dailyTicksMock{},
+		LockTimeout: 30 * time.Millisecond, UpdateWorkers: 1}
+
+	// Other process updates SPY
+	lock, err := storage.lockSymbolUpdate("SPY")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := TickUpdateParams{Symbol: "SPY", FromDate: timeOnTheFly(2018, 11, 5),
+		ToDate: timeOnTheFly(2018, 11, 6), Quotes: true, Trades: true}
+	err = storage.UpdateSymbolTicks(params)
+	assert.IsType(t, &ErrLockTimeout{}, err)
+
+	// Interrupted update of running process isn't recovered
+	_, err = storage.journal().begin(journalEntry{Dataset: ticksDataset, Symbol: "SPY",
+		Data: path.Join(testDir, "ticks/quotes_trades/SPY"), Meta: path.Join(testDir, "ticks/quotes_trades/.meta/SPY.json")})
+	assert.Nil(t, err)
+	report, err := storage.Recover(false)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(report.RebuiltMeta))
+
+	lock.Unlock()
+
+	report, err = storage.Recover(false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(report.RebuiltMeta))
+
+	err = storage.UpdateSymbolTicks(params)
+	assert.Nil(t, err)
+
+	// Reader waits for writer
+	dataLock, err := storage.lockSymbolData("SPY", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = storage.GetStoredTicks("SPY", DateRange{timeOnTheFly(2018, 11, 5), timeOnTheFly(2018, 11, 6)}, true, true)
+	assert.IsType(t, &ErrLockTimeout{}, err)
+	dataLock.Unlock()
+
+	ticks, err := storage.GetStoredTicks("SPY", DateRange{timeOnTheFly(2018, 11, 5), timeOnTheFly(2018, 11, 6)}, true, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ticks))
+}
@@ -0,0 +1,36 @@
+//go:build windows
+// +build windows
+
+package marketdata
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// The whole file range is locked. Lock is mandatory on Windows, but lock files are never read or written
+const lockRangeBytes = ^uint32(0)
+
+// Tries To take lock without blocking. Returns false if lock is held by someone else
+func tryFlock(f *os.File, exclusive bool) (bool, error) {
+	flags := uint32(windows.LOCKFILE_FAIL_IMMEDIATELY)
+	if exclusive {
+		flags |= windows.LOCKFILE_EXCLUSIVE_LOCK
+	}
+
+	ol := windows.Overlapped{}
+	err := windows.LockFileEx(windows.Handle(f.Fd()), flags, 0, lockRangeBytes, lockRangeBytes, &ol)
+	if err == windows.ERROR_LOCK_VIOLATION || err == windows.ERROR_IO_PENDING {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func funlock(f *os.File) error {
+	ol := windows.Overlapped{}
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, lockRangeBytes, lockRangeBytes, &ol)
+}
@@ -0,0 +1,235 @@
+package marketdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	manifestFileName  = "manifest.json"
+	jsonStorageFormat = "json"
+
+	// Storage without manifest. Option symbols are stored flat: ticks/quotes_trades/AAPL210917C00150000/...
+	LegacyLayoutVersion = 1
+	// Option symbols are grouped by underlying and expiry: ticks/quotes_trades/AAPL/2021-09-17/AAPL210917C00150000/...
+	CurrentLayoutVersion = 2
+)
+
+// Manifest in storage root. It records layout of files and settings which data was stored with
+type StorageManifest struct {
+	LayoutVersion int
+	Format        string
+	TimeZone      string
+	Market        MarketMode
+	DailyRollHour int
+	Created       time.Time
+	Migrated      time.Time `json:",omitempty"`
+}
+
+type ErrLayoutVersion struct {
+	path    string
+	version int
+}
+
+func (e *ErrLayoutVersion) Error() string {
+	if e.version < CurrentLayoutVersion {
+		return fmt.Sprintf("Storage %v has layout version %v, current is %v. Migrate it with JsonStorage.Migrate",
+			e.path, e.version, CurrentLayoutVersion)
+	}
+	return fmt.Sprintf("Storage %v has layout version %v which is newer than supported %v",
+		e.path, e.version, CurrentLayoutVersion)
+}
+
+type ErrManifestMismatch struct {
+	path       string
+	setting    string
+	stored     interface{}
+	configured interface{}
+}
+
+func (e *ErrManifestMismatch) Error() string {
+	return fmt.Sprintf("Storage %v was created with %v %v, but storage is configured with %v", e.path, e.setting,
+		e.stored, e.configured)
+}
+
+// Manifest for current layout and settings of storage
+func (p *JsonStorage) newManifest() *StorageManifest {
+	m := StorageManifest{
+		LayoutVersion: CurrentLayoutVersion,
+		Format:        jsonStorageFormat,
+		TimeZone:      p.TimeZone.String(),
+		Market:        p.marketMode(),
+		Created:       time.Now().UTC(),
+	}
+	if m.Market == AllDaysMarket {
+		m.DailyRollHour = p.DailyRollHour
+	}
+	return &m
+}
+
+func (p *JsonStorage) manifestPath() string {
+	return path.Join(p.Path, manifestFileName)
+}
+
+// Returns manifest of storage. Storage which has data but no manifest has legacy layout, manifest of empty storage
+// is nil
+func (p *JsonStorage) Manifest() (*StorageManifest, error) {
+	if !fileExists(p.manifestPath()) {
+		if p.hasData() {
+			return &StorageManifest{LayoutVersion: LegacyLayoutVersion, Format: jsonStorageFormat}, nil
+		}
+		return nil, nil
+	}
+	return loadManifest(p.manifestPath())
+}
+
+func loadManifest(pth string) (*StorageManifest, error) {
+	data, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return nil, err
+	}
+	m := StorageManifest{}
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (m *StorageManifest) save(pth string) error {
+	json_, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(pth, json_, 0644)
+}
+
+// Creates manifest of new storage or checks that storage has current layout and the same settings
+func (p *JsonStorage) checkManifest() error {
+	m, err := p.Manifest()
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		return p.newManifest().save(p.manifestPath())
+	}
+
+	if m.LayoutVersion != CurrentLayoutVersion {
+		return &ErrLayoutVersion{p.Path, m.LayoutVersion}
+	}
+	return p.checkSettings(m)
+}
+
+// Manifests which were already checked, by absolute storage path. Storage which isn't opened with Open is checked
+// on the first read or update, later calls only compare manifest file with the checked one
+var checkedManifests = struct {
+	sync.Mutex
+	m map[string]checkedManifest
+}{m: make(map[string]checkedManifest)}
+
+type checkedManifest struct {
+	settings StorageManifest
+	modTime  time.Time
+	size     int64
+}
+
+// Checks manifest once for read and update paths. Update creates manifest of new storage, read of storage without
+// manifest and data is allowed and doesn't create it
+func (p *JsonStorage) ensureManifest(update bool) error {
+	err := checkMarketMode(p.Market, p.HasWeekends)
+	if err != nil {
+		return err
+	}
+	key, err := filepath.Abs(p.Path)
+	if err != nil {
+		return err
+	}
+	settings := *p.newManifest()
+	settings.Created = time.Time{}
+
+	info, statErr := os.Stat(p.manifestPath())
+	checkedManifests.Lock()
+	checked, ok := checkedManifests.m[key]
+	checkedManifests.Unlock()
+	if ok && statErr == nil && checked.settings == settings && checked.modTime.Equal(info.ModTime()) &&
+		checked.size == info.Size() {
+		return nil
+	}
+
+	if update {
+		err = p.checkManifest()
+	} else {
+		err = p.checkStoredManifest()
+	}
+	if err != nil {
+		return err
+	}
+
+	info, statErr = os.Stat(p.manifestPath())
+	if statErr != nil {
+		// Empty storage without manifest, it's checked again next time
+		return nil
+	}
+	checkedManifests.Lock()
+	checkedManifests.m[key] = checkedManifest{settings, info.ModTime(), info.Size()}
+	checkedManifests.Unlock()
+	return nil
+}
+
+// Checks that storage has current layout and the same settings. Manifest isn't created
+func (p *JsonStorage) checkStoredManifest() error {
+	m, err := p.Manifest()
+	if err != nil || m == nil {
+		return err
+	}
+	if m.LayoutVersion != CurrentLayoutVersion {
+		return &ErrLayoutVersion{p.Path, m.LayoutVersion}
+	}
+	return p.checkSettings(m)
+}
+
+func (p *JsonStorage) checkSettings(m *StorageManifest) error {
+	current := p.newManifest()
+	if m.Format != current.Format {
+		return &ErrManifestMismatch{p.Path, "format", m.Format, current.Format}
+	}
+	if m.TimeZone != current.TimeZone {
+		return &ErrManifestMismatch{p.Path, "time zone", m.TimeZone, current.TimeZone}
+	}
+	if m.Market != current.Market {
+		return &ErrManifestMismatch{p.Path, "market", m.Market, current.Market}
+	}
+	if m.DailyRollHour != current.DailyRollHour {
+		return &ErrManifestMismatch{p.Path, "daily roll hour", m.DailyRollHour, current.DailyRollHour}
+	}
+	return nil
+}
+
+// Storage has data if there is any data file in ticks or candles
+func (p *JsonStorage) hasData() bool {
+	found := false
+	for _, dataset := range []string{ticksDataset, candlesDataset} {
+		root := path.Join(p.Path, dataset)
+		if !fileExists(root) {
+			continue
+		}
+		filepath.Walk(root, func(pth string, info os.FileInfo, err error) error {
+			if err != nil || found {
+				return filepath.SkipDir
+			}
+			if !info.IsDir() && strings.HasSuffix(info.Name(), ".json") {
+				found = true
+				return filepath.SkipDir
+			}
+			return nil
+		})
+	}
+	return found
+}
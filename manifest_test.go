@@ -0,0 +1,112 @@
+package marketdata
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJsonStorage_OpenChecksManifest(t *testing.T) {
+	testDir := "./test_data/manifest"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC, Market: WeekdaysMarket}
+	_, err := storage.Open()
+	assert.Nil(t, err)
+
+	m, err := storage.Manifest()
+	assert.Nil(t, err)
+	assert.Equal(t, CurrentLayoutVersion, m.LayoutVersion)
+	assert.Equal(t, "UTC", m.TimeZone)
+	assert.Equal(t, WeekdaysMarket, m.Market)
+
+	// Reopen with the same settings
+	_, err = (&JsonStorage{Path: testDir, TimeZone: time.UTC}).Open()
+	assert.Nil(t, err)
+
+	// Legacy HasWeekends means 24/7 market
+	_, err = (&JsonStorage{Path: testDir, TimeZone: time.UTC, HasWeekends: true}).Open()
+	assert.IsType(t, &ErrManifestMismatch{}, err)
+	_, err = (&JsonStorage{Path: testDir, TimeZone: time.UTC, HasWeekends: true, Market: WeekdaysMarket}).Open()
+	assert.IsType(t, &ErrMarketConflict{}, err)
+	_, err = (&JsonStorage{Path: testDir, TimeZone: time.UTC, HasWeekends: true, Market: WeekdaysMarket}).
+		GetStoredTicks("SPY", DateRange{}, true, true)
+	assert.IsType(t, &ErrMarketConflict{}, err)
+
+	ny, _ := time.LoadLocation("America/New_York")
+	_, err = (&JsonStorage{Path: testDir, TimeZone: ny, Market: WeekdaysMarket}).Open()
+	assert.IsType(t, &ErrManifestMismatch{}, err)
+
+	_, err = (&JsonStorage{Path: testDir, TimeZone: time.UTC, Market: AllDaysMarket}).Open()
+	assert.IsType(t, &ErrManifestMismatch{}, err)
+
+	m.LayoutVersion = CurrentLayoutVersion + 1
+	m.save(storage.manifestPath())
+	_, err = storage.Open()
+	assert.IsType(t, &ErrLayoutVersion{}, err)
+}
+
+func TestJsonStorage_OpenLegacy(t *testing.T) {
+	testDir := "./test_data/manifest_legacy"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC, Market: WeekdaysMarket}
+	candles := CandleArray{}
+	err := storage.saveCandlesToFile(&candles, path.Join(testDir, "candles/day/SPY.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = storage.Open()
+	assert.IsType(t, &ErrLayoutVersion{}, err)
+	assert.False(t, fileExists(storage.manifestPath()))
+}
+
+func TestJsonStorage_DataPathsCheckManifest(t *testing.T) {
+	testDir := "./test_data/manifest_data_paths"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	// Storage which isn't opened creates manifest on the first update
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC, Market: WeekdaysMarket, Provider: &quotesTradesMock{}}
+	day := timeOnTheFly(2018, 11, 1)
+	err := storage.UpdateSymbolTicks(TickUpdateParams{Symbol: "SPY", FromDate: day, ToDate: day, Quotes: true,
+		Trades: true})
+	assert.Nil(t, err)
+	m, err := storage.Manifest()
+	assert.Nil(t, err)
+	assert.Equal(t, CurrentLayoutVersion, m.LayoutVersion)
+
+	ticks, err := storage.GetStoredTicks("SPY", DateRange{day, day}, true, true)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, ticks)
+
+	// Manifest changed after it was checked
+	m.LayoutVersion = CurrentLayoutVersion + 1
+	m.save(storage.manifestPath())
+	_, err = storage.GetStoredTicks("SPY", DateRange{day, day}, true, true)
+	assert.IsType(t, &ErrLayoutVersion{}, err)
+	_, err = storage.GetStoredCandles("SPY", "D", DateRange{})
+	assert.IsType(t, &ErrLayoutVersion{}, err)
+	err = storage.UpdateSymbolTicks(TickUpdateParams{Symbol: "SPY", FromDate: day, ToDate: day, Quotes: true,
+		Trades: true})
+	assert.IsType(t, &ErrLayoutVersion{}, err)
+
+	m.LayoutVersion = CurrentLayoutVersion
+	m.save(storage.manifestPath())
+	ny, _ := time.LoadLocation("America/New_York")
+	_, err = (&JsonStorage{Path: testDir, TimeZone: ny, Market: WeekdaysMarket}).GetStoredTicks("SPY",
+		DateRange{day, day}, true, true)
+	assert.IsType(t, &ErrManifestMismatch{}, err)
+
+	// Read of legacy storage fails and doesn't create manifest
+	os.Remove(storage.manifestPath())
+	_, err = storage.GetStoredTicks("SPY", DateRange{day, day}, true, true)
+	assert.IsType(t, &ErrLayoutVersion{}, err)
+	assert.False(t, fileExists(storage.manifestPath()))
+}
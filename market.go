@@ -0,0 +1,174 @@
+package marketdata
+
+import (
+	"fmt"
+	"time"
+)
+
+// Which calendar days market is expected To have data
+type MarketMode string
+
+const (
+	// Monday - Friday. Weekends are never requested and never reported as gaps
+	WeekdaysMarket MarketMode = "weekdays"
+	// 24/7 market (crypto). Every calendar day is expected and current day can be updated up To the last
+	// completed hour
+	AllDaysMarket MarketMode = "24x7"
+)
+
+// Legacy HasWeekends flag is set, but Market is set To mode without weekends
+type ErrMarketConflict struct {
+	market MarketMode
+}
+
+func (e *ErrMarketConflict) Error() string {
+	return fmt.Sprintf("HasWeekends means %v market, but Market is %v", AllDaysMarket, e.market)
+}
+
+// Returns mode set explicitly or mode of legacy HasWeekends flag: storage with weekends expects every calendar
+// day. Storage without both is weekday market.
+func resolveMarketMode(mode MarketMode, hasWeekends bool) MarketMode {
+	if mode != "" {
+		return mode
+	}
+	if hasWeekends {
+		return AllDaysMarket
+	}
+	return WeekdaysMarket
+}
+
+// HasWeekends can't be combined with weekday market
+func checkMarketMode(mode MarketMode, hasWeekends bool) error {
+	if hasWeekends && resolveMarketMode(mode, hasWeekends) != AllDaysMarket {
+		return &ErrMarketConflict{mode}
+	}
+	return nil
+}
+
+func (m MarketMode) isTradingDay(d time.Time) bool {
+	if m == WeekdaysMarket && (d.Weekday() == time.Saturday || d.Weekday() == time.Sunday) {
+		return false
+	}
+	return true
+}
+
+// Last completed hour of 24/7 market. Everything before it can be stored
+func lastCompletedHour(now time.Time) time.Time {
+	return now.Truncate(time.Hour)
+}
+
+// Start of daily candle which contains t. Day rolls at rollHour UTC, candle is labeled by date of its end:
+// with rollHour=22 candle of 2018-11-02 starts at 2018-11-01 22:00 UTC.
+func dailyCandleStart(t time.Time, rollHour int) time.Time {
+	u := t.UTC()
+	start := time.Date(u.Year(), u.Month(), u.Day(), rollHour, 0, 0, 0, time.UTC)
+	if start.After(u) {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start
+}
+
+func dailyCandleLabel(start time.Time, rollHour int) time.Time {
+	if rollHour == 0 {
+		return start
+	}
+	end := start.AddDate(0, 0, 1)
+	return time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Aggregates intraday candles into daily ones. Day rolls at rollHour UTC (0 - midnight UTC)
+func AggregateDailyCandles(candles CandleArray, rollHour int) CandleArray {
+	sorted := sortedCopy(candles)
+
+	var daily CandleArray
+	var current *Candle
+	var currentStart time.Time
+
+	for _, c := range sorted {
+		start := dailyCandleStart(c.Datetime, rollHour)
+		if current == nil || !start.Equal(currentStart) {
+			current = &Candle{
+				Symbol:   c.Symbol,
+				Open:     c.Open,
+				High:     c.High,
+				Low:      c.Low,
+				Datetime: dailyCandleLabel(start, rollHour),
+				Source:   c.Source,
+			}
+			currentStart = start
+			daily = append(daily, current)
+		}
+
+		if c.High > current.High {
+			current.High = c.High
+		}
+		if c.Low < current.Low {
+			current.Low = c.Low
+		}
+		current.Close = c.Close
+		current.AdjClose = c.AdjClose
+		current.Volume += c.Volume
+		current.OpenInterest = c.OpenInterest
+	}
+
+	return daily
+}
+
+// Aggregates trades into daily candles. Day rolls at rollHour UTC (0 - midnight UTC). Quotes are ignored, trades
+// update candle according To their tape rules
+func AggregateDailyTicks(ticks TickArray, rollHour int) CandleArray {
+	var daily CandleArray
+	var current *Candle
+	var currentStart time.Time
+
+	for _, t := range ticks {
+		if !t.HasTrade() {
+			continue
+		}
+
+		start := dailyCandleStart(t.Datetime, rollHour)
+		if current == nil || !start.Equal(currentStart) {
+			if current != nil && hasTradePrices(current) {
+				daily = append(daily, current)
+			}
+			current = &Candle{Symbol: t.Symbol, Datetime: dailyCandleLabel(start, rollHour), Source: t.Source}
+			currentStart = start
+		}
+		applyTrade(current, t)
+	}
+	if current != nil && hasTradePrices(current) {
+		daily = append(daily, current)
+	}
+
+	return daily
+}
+
+// Updates candle by trade according To its tape rule. Prices of candle are zero until the first print which is
+// allowed To set them: Open is the first print which updates last, High and Low are set by prints which update
+// high and low only.
+func applyTrade(c *Candle, t *Tick) {
+	rule := t.TapeRule()
+	if rule.UpdatesHighLow {
+		if c.High == 0 || t.LastPrice > c.High {
+			c.High = t.LastPrice
+		}
+		if c.Low == 0 || t.LastPrice < c.Low {
+			c.Low = t.LastPrice
+		}
+	}
+	if rule.UpdatesLast {
+		if c.Open == 0 {
+			c.Open = t.LastPrice
+		}
+		c.Close = t.LastPrice
+		c.AdjClose = t.LastPrice
+	}
+	if rule.UpdatesVolume {
+		c.Volume += t.LastSize
+	}
+}
+
+// Candle built From trades has prices only if some of its prints were eligible To set them
+func hasTradePrices(c *Candle) bool {
+	return c.High > 0 && c.Close > 0
+}
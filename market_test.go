@@ -0,0 +1,108 @@
+package marketdata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveMarketMode(t *testing.T) {
+	assert.Equal(t, WeekdaysMarket, resolveMarketMode("", false))
+	assert.Equal(t, AllDaysMarket, resolveMarketMode(AllDaysMarket, false))
+
+	// Legacy storage with weekends expects every calendar day
+	assert.Equal(t, AllDaysMarket, resolveMarketMode("", true))
+	assert.Equal(t, AllDaysMarket, (&JsonStorage{HasWeekends: true}).marketMode())
+	assert.Nil(t, checkMarketMode("", true))
+	assert.Nil(t, checkMarketMode(AllDaysMarket, true))
+	assert.IsType(t, &ErrMarketConflict{}, checkMarketMode(WeekdaysMarket, true))
+
+	// Zero value storage keeps weekday calendar
+	assert.Equal(t, WeekdaysMarket, (&JsonStorage{}).marketMode())
+	assert.Equal(t, WeekdaysMarket, (&JsonSymbolMeta{}).marketMode())
+
+	saturday := timeOnTheFly(2018, 11, 3)
+	assert.False(t, WeekdaysMarket.isTradingDay(saturday))
+	assert.True(t, AllDaysMarket.isTradingDay(saturday))
+
+	meta := JsonSymbolMeta{Market: AllDaysMarket, HasWeekends: true}
+	emptyDates, err := meta.getEmptyDates(&DateRange{timeOnTheFly(2018, 11, 2), timeOnTheFly(2018, 11, 5)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 4, len(emptyDates))
+}
+
+func TestAggregateDailyCandles(t *testing.T) {
+	var hourly CandleArray
+	start := time.Date(2018, 11, 1, 20, 0, 0, 0, time.UTC)
+	for i := 0; i < 6; i++ {
+		price := float64(100 + i)
+		hourly = append(hourly, &Candle{Symbol: "BTCUSD", Open: price, High: price + 1, Low: price - 1,
+			Close: price, AdjClose: price, Volume: 10, Datetime: start.Add(time.Duration(i) * time.Hour)})
+	}
+
+	daily := AggregateDailyCandles(hourly, 0)
+	assert.Equal(t, 2, len(daily))
+	assert.Equal(t, timeOnTheFly(2018, 11, 1), daily[0].Datetime)
+	assert.Equal(t, int64(40), daily[0].Volume)
+	assert.Equal(t, 103.0, daily[0].Close)
+
+	// Day rolls at 22:00 UTC. 20:00 and 21:00 belong To 2018-11-01, the rest To 2018-11-02
+	daily = AggregateDailyCandles(hourly, 22)
+	assert.Equal(t, 2, len(daily))
+	assert.Equal(t, timeOnTheFly(2018, 11, 1), daily[0].Datetime)
+	assert.Equal(t, timeOnTheFly(2018, 11, 2), daily[1].Datetime)
+	assert.Equal(t, 102.0, daily[1].Open)
+	assert.Equal(t, 106.0, daily[1].High)
+	assert.Equal(t, int64(40), daily[1].Volume)
+
+	ticks := TickArray{
+		{LastPrice: 10, LastSize: 1, Datetime: time.Date(2018, 11, 1, 21, 0, 0, 0, time.UTC)},
+		{LastPrice: 12, LastSize: 2, Datetime: time.Date(2018, 11, 1, 23, 0, 0, 0, time.UTC)},
+		{LastPrice: 11, LastSize: 3, Datetime: time.Date(2018, 11, 2, 1, 0, 0, 0, time.UTC)},
+	}
+	daily = AggregateDailyTicks(ticks, 22)
+	assert.Equal(t, 2, len(daily))
+	assert.Equal(t, int64(5), daily[1].Volume)
+	assert.Equal(t, 11.0, daily[1].Close)
+
+	// Odd lot and average price prints don't set prices, day with only odd lots has no candle
+	at := func(day int, hour int, price float64, cond string) *Tick {
+		return &Tick{LastPrice: price, LastSize: 10, Cond1: cond,
+			Datetime: time.Date(2018, 11, day, hour, 0, 0, 0, time.UTC)}
+	}
+	ticks = TickArray{
+		at(1, 10, 9, "37"),
+		at(1, 11, 10, "0"),
+		at(1, 12, 50, "2"),
+		at(1, 13, 10.5, "0"),
+		at(2, 10, 9, "37"),
+	}
+	daily = AggregateDailyTicks(ticks, 0)
+	assert.Equal(t, 1, len(daily))
+	assert.Equal(t, 10.0, daily[0].Open)
+	assert.Equal(t, 10.5, daily[0].High)
+	assert.Equal(t, 10.0, daily[0].Low)
+	assert.Equal(t, 10.5, daily[0].Close)
+	assert.Equal(t, int64(40), daily[0].Volume)
+}
+
+func TestJsonStorage_cutToCompletedHour(t *testing.T) {
+	storage := JsonStorage{TimeZone: time.UTC, Market: AllDaysMarket}
+
+	now := time.Now().UTC()
+	today := setTimeToSOD(now)
+	r, ok := storage.cutToCompletedHour(DateRange{today, today.Add(24*time.Hour - time.Second)})
+
+	if now.Hour() == 0 {
+		assert.False(t, ok)
+		return
+	}
+	assert.True(t, ok)
+	assert.Equal(t, now.Truncate(time.Hour).Add(-time.Second), r.To)
+
+	dates := storage.completedDates([]time.Time{today.AddDate(0, 0, -1), today})
+	assert.Equal(t, []time.Time{today.AddDate(0, 0, -1)}, dates)
+}
@@ -0,0 +1,277 @@
+//go:build grpc
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: marketdata.proto
+
+package marketdata
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	MarketData_GetCandles_FullMethodName  = "/marketdata.MarketData/GetCandles"
+	MarketData_GetSnapshot_FullMethodName = "/marketdata.MarketData/GetSnapshot"
+	MarketData_ReplayTicks_FullMethodName = "/marketdata.MarketData/ReplayTicks"
+	MarketData_StreamTicks_FullMethodName = "/marketdata.MarketData/StreamTicks"
+)
+
+// MarketDataClient is the client API for MarketData service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MarketDataClient interface {
+	GetCandles(ctx context.Context, in *CandlesRequest, opts ...grpc.CallOption) (*CandlesResponse, error)
+	GetSnapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*SnapshotResponse, error)
+	ReplayTicks(ctx context.Context, in *TicksRequest, opts ...grpc.CallOption) (MarketData_ReplayTicksClient, error)
+	StreamTicks(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (MarketData_StreamTicksClient, error)
+}
+
+type marketDataClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMarketDataClient(cc grpc.ClientConnInterface) MarketDataClient {
+	return &marketDataClient{cc}
+}
+
+func (c *marketDataClient) GetCandles(ctx context.Context, in *CandlesRequest, opts ...grpc.CallOption) (*CandlesResponse, error) {
+	out := new(CandlesResponse)
+	err := c.cc.Invoke(ctx, MarketData_GetCandles_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketDataClient) GetSnapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*SnapshotResponse, error) {
+	out := new(SnapshotResponse)
+	err := c.cc.Invoke(ctx, MarketData_GetSnapshot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketDataClient) ReplayTicks(ctx context.Context, in *TicksRequest, opts ...grpc.CallOption) (MarketData_ReplayTicksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &MarketData_ServiceDesc.Streams[0], MarketData_ReplayTicks_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &marketDataReplayTicksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MarketData_ReplayTicksClient interface {
+	Recv() (*TicksResponse, error)
+	grpc.ClientStream
+}
+
+type marketDataReplayTicksClient struct {
+	grpc.ClientStream
+}
+
+func (x *marketDataReplayTicksClient) Recv() (*TicksResponse, error) {
+	m := new(TicksResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *marketDataClient) StreamTicks(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (MarketData_StreamTicksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &MarketData_ServiceDesc.Streams[1], MarketData_StreamTicks_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &marketDataStreamTicksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MarketData_StreamTicksClient interface {
+	Recv() (*TicksResponse, error)
+	grpc.ClientStream
+}
+
+type marketDataStreamTicksClient struct {
+	grpc.ClientStream
+}
+
+func (x *marketDataStreamTicksClient) Recv() (*TicksResponse, error) {
+	m := new(TicksResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MarketDataServer is the server API for MarketData service.
+// All implementations must embed UnimplementedMarketDataServer
+// for forward compatibility
+type MarketDataServer interface {
+	GetCandles(context.Context, *CandlesRequest) (*CandlesResponse, error)
+	GetSnapshot(context.Context, *SnapshotRequest) (*SnapshotResponse, error)
+	ReplayTicks(*TicksRequest, MarketData_ReplayTicksServer) error
+	StreamTicks(*SubscribeRequest, MarketData_StreamTicksServer) error
+	mustEmbedUnimplementedMarketDataServer()
+}
+
+// UnimplementedMarketDataServer must be embedded to have forward compatible implementations.
+type UnimplementedMarketDataServer struct {
+}
+
+func (UnimplementedMarketDataServer) GetCandles(context.Context, *CandlesRequest) (*CandlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCandles not implemented")
+}
+func (UnimplementedMarketDataServer) GetSnapshot(context.Context, *SnapshotRequest) (*SnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSnapshot not implemented")
+}
+func (UnimplementedMarketDataServer) ReplayTicks(*TicksRequest, MarketData_ReplayTicksServer) error {
+	return status.Errorf(codes.Unimplemented, "method ReplayTicks not implemented")
+}
+func (UnimplementedMarketDataServer) StreamTicks(*SubscribeRequest, MarketData_StreamTicksServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTicks not implemented")
+}
+func (UnimplementedMarketDataServer) mustEmbedUnimplementedMarketDataServer() {}
+
+// UnsafeMarketDataServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MarketDataServer will
+// result in compilation errors.
+type UnsafeMarketDataServer interface {
+	mustEmbedUnimplementedMarketDataServer()
+}
+
+func RegisterMarketDataServer(s grpc.ServiceRegistrar, srv MarketDataServer) {
+	s.RegisterService(&MarketData_ServiceDesc, srv)
+}
+
+func _MarketData_GetCandles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CandlesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServer).GetCandles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketData_GetCandles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServer).GetCandles(ctx, req.(*CandlesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketData_GetSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServer).GetSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketData_GetSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServer).GetSnapshot(ctx, req.(*SnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketData_ReplayTicks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TicksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MarketDataServer).ReplayTicks(m, &marketDataReplayTicksServer{stream})
+}
+
+type MarketData_ReplayTicksServer interface {
+	Send(*TicksResponse) error
+	grpc.ServerStream
+}
+
+type marketDataReplayTicksServer struct {
+	grpc.ServerStream
+}
+
+func (x *marketDataReplayTicksServer) Send(m *TicksResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _MarketData_StreamTicks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MarketDataServer).StreamTicks(m, &marketDataStreamTicksServer{stream})
+}
+
+type MarketData_StreamTicksServer interface {
+	Send(*TicksResponse) error
+	grpc.ServerStream
+}
+
+type marketDataStreamTicksServer struct {
+	grpc.ServerStream
+}
+
+func (x *marketDataStreamTicksServer) Send(m *TicksResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// MarketData_ServiceDesc is the grpc.ServiceDesc for MarketData service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MarketData_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "marketdata.MarketData",
+	HandlerType: (*MarketDataServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCandles",
+			Handler:    _MarketData_GetCandles_Handler,
+		},
+		{
+			MethodName: "GetSnapshot",
+			Handler:    _MarketData_GetSnapshot_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ReplayTicks",
+			Handler:       _MarketData_ReplayTicks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamTicks",
+			Handler:       _MarketData_StreamTicks_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "marketdata.proto",
+}
@@ -0,0 +1,313 @@
+package marketdata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Step of layout upgrade. Migrations only move files, so step is described by new relative path of every file.
+// movePath should return the same path for file which is already moved, so interrupted migration can be repeated
+type layoutMigration struct {
+	From        int
+	To          int
+	Description string
+	movePath    func(rel string) string
+}
+
+var layoutMigrations = []layoutMigration{
+	{LegacyLayoutVersion, 2, "Group option symbols by underlying and expiry", groupOptionSymbolPath},
+}
+
+type MigrationReport struct {
+	Path    string // Migrated storage
+	From    int
+	To      int
+	Applied []string
+	Moved   []string // Old relative paths of moved files
+	Files   int      // Number of verified files
+}
+
+type ErrMigrationVerification struct {
+	path   string
+	reason string
+}
+
+func (e *ErrMigrationVerification) Error() string {
+	return fmt.Sprintf("Migration verification failed for %v: %v", e.path, e.reason)
+}
+
+// Upgrades storage To current layout. If dst is empty storage is migrated in place, otherwise migrated copy is
+// written To dst and source is left untouched. Content of every file is verified after migration. Storage shouldn't
+// be updated by other processes during migration.
+func (p *JsonStorage) Migrate(dst string) (*MigrationReport, error) {
+	m, err := p.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		m = p.newManifest()
+	}
+	if m.LayoutVersion > CurrentLayoutVersion {
+		return nil, &ErrLayoutVersion{p.Path, m.LayoutVersion}
+	}
+	if m.LayoutVersion == LegacyLayoutVersion {
+		// Legacy storage has no settings, it's assumed that it was used with the current ones
+		m = p.newManifest()
+		m.LayoutVersion = LegacyLayoutVersion
+	}
+
+	pending, err := p.journal().pending()
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) > 0 {
+		return nil, errors.New("Storage has interrupted updates. Recover it before migration")
+	}
+
+	lock, err := acquireLock(path.Join(p.Path, locksFolder, "migration.lock"), true, p.lockTimeout())
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	var steps []layoutMigration
+	for _, s := range layoutMigrations {
+		if s.From >= m.LayoutVersion {
+			steps = append(steps, s)
+		}
+	}
+
+	report := MigrationReport{Path: p.Path, From: m.LayoutVersion, To: CurrentLayoutVersion}
+	if dst != "" {
+		report.Path = dst
+	}
+
+	checksums, err := storageChecksums(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	expected, err := migratedPaths(checksums, steps)
+	if err != nil {
+		return nil, err
+	}
+
+	if dst == "" {
+		err = p.migrateInPlace(m, steps, &report)
+	} else {
+		err = copyMigrated(p.Path, dst, checksums, steps, &report)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "Migrate() From version %v", report.From)
+	}
+
+	err = verifyMigration(report.Path, expected)
+	if err != nil {
+		return nil, err
+	}
+	report.Files = len(expected)
+
+	m.LayoutVersion = CurrentLayoutVersion
+	if len(steps) > 0 {
+		m.Migrated = time.Now().UTC()
+	}
+	return &report, m.save(path.Join(report.Path, manifestFileName))
+}
+
+// Manifest is saved after every step, so interrupted migration continues From the step it was interrupted at
+func (p *JsonStorage) migrateInPlace(m *StorageManifest, steps []layoutMigration, report *MigrationReport) error {
+	for _, s := range steps {
+		checksums, err := storageChecksums(p.Path)
+		if err != nil {
+			return err
+		}
+
+		var files []string
+		for rel := range checksums {
+			files = append(files, rel)
+		}
+		sort.Strings(files)
+
+		for _, rel := range files {
+			moved := s.movePath(rel)
+			if moved == rel {
+				continue
+			}
+			err = os.MkdirAll(filepath.Dir(path.Join(p.Path, moved)), os.ModePerm)
+			if err != nil {
+				return err
+			}
+			err = os.Rename(path.Join(p.Path, rel), path.Join(p.Path, moved))
+			if err != nil {
+				return err
+			}
+			// Old folder of symbol is removed when it becomes empty
+			os.Remove(filepath.Dir(path.Join(p.Path, rel)))
+			report.Moved = append(report.Moved, rel)
+		}
+
+		m.LayoutVersion = s.To
+		m.Migrated = time.Now().UTC()
+		err = m.save(p.manifestPath())
+		if err != nil {
+			return err
+		}
+		report.Applied = append(report.Applied, s.Description)
+	}
+	return nil
+}
+
+func copyMigrated(src string, dst string, checksums map[string]string, steps []layoutMigration,
+	report *MigrationReport) error {
+
+	if files, err := ioutil.ReadDir(dst); err == nil && len(files) > 0 {
+		return errors.Errorf("Destination %v isn't empty", dst)
+	}
+
+	var files []string
+	for rel := range checksums {
+		files = append(files, rel)
+	}
+	sort.Strings(files)
+
+	for _, rel := range files {
+		data, err := ioutil.ReadFile(path.Join(src, rel))
+		if err != nil {
+			return err
+		}
+		moved := rel
+		for _, s := range steps {
+			moved = s.movePath(moved)
+		}
+		err = writeFileAtomic(path.Join(dst, moved), data, 0644)
+		if err != nil {
+			return err
+		}
+		if moved != rel {
+			report.Moved = append(report.Moved, rel)
+		}
+	}
+
+	for _, s := range steps {
+		report.Applied = append(report.Applied, s.Description)
+	}
+	return nil
+}
+
+// Checksums of files after migration by their new relative paths
+func migratedPaths(checksums map[string]string, steps []layoutMigration) (map[string]string, error) {
+	expected := make(map[string]string)
+	for rel, sum := range checksums {
+		moved := rel
+		for _, s := range steps {
+			moved = s.movePath(moved)
+		}
+		if _, ok := expected[moved]; ok {
+			return nil, errors.Errorf("Migration moves two files To %v", moved)
+		}
+		expected[moved] = sum
+	}
+	return expected, nil
+}
+
+func verifyMigration(root string, expected map[string]string) error {
+	actual, err := storageChecksums(root)
+	if err != nil {
+		return err
+	}
+	for rel, sum := range expected {
+		got, ok := actual[rel]
+		if !ok {
+			return &ErrMigrationVerification{rel, "file is missing"}
+		}
+		if got != sum {
+			return &ErrMigrationVerification{rel, "content differs"}
+		}
+	}
+	for rel := range actual {
+		if _, ok := expected[rel]; !ok {
+			return &ErrMigrationVerification{rel, "unexpected file"}
+		}
+	}
+	return nil
+}
+
+// Checksums of all storage files by relative path. Manifest, journal, locks, quarantine and temp files are skipped
+func storageChecksums(root string) (map[string]string, error) {
+	checksums := make(map[string]string)
+	if !fileExists(root) {
+		return checksums, nil
+	}
+
+	err := filepath.Walk(root, func(pth string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, pth)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		topLevel := !strings.Contains(rel, "/")
+
+		if info.IsDir() {
+			if topLevel && rel != "." && strings.HasPrefix(rel, ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isTempFile(info.Name()) || (topLevel && (strings.HasPrefix(rel, ".") || rel == manifestFileName)) {
+			return nil
+		}
+
+		data, err := ioutil.ReadFile(pth)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		checksums[rel] = hex.EncodeToString(sum[:])
+		return nil
+	})
+	return checksums, err
+}
+
+// Version 1 -> 2: ticks/<folder>/AAPL210917C00150000/<date>.json becomes
+// ticks/<folder>/AAPL/2021-09-17/AAPL210917C00150000/<date>.json. Meta and daily candles are moved the same way
+func groupOptionSymbolPath(rel string) string {
+	parts := strings.Split(rel, "/")
+	if len(parts) < 3 || (parts[0] != ticksDataset && parts[0] != candlesDataset) {
+		return rel
+	}
+
+	i := 2
+	if parts[i] == metaFolder {
+		i++
+	}
+	if i >= len(parts) {
+		return rel
+	}
+
+	leaf := i == len(parts)-1
+	symbol := parts[i]
+	if leaf {
+		symbol = strings.TrimSuffix(symbol, ".json")
+	}
+	if !isOptionSymbol(symbol) {
+		return rel
+	}
+
+	parts[i] = symbolStoragePath(symbol)
+	if leaf {
+		parts[i] += ".json"
+	}
+	return path.Join(parts...)
+}
@@ -0,0 +1,124 @@
+package marketdata
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupOptionSymbolPath(t *testing.T) {
+	cases := map[string]string{
+		"ticks/quotes_trades/AAPL210917C00150000/2021-09-01.json": "ticks/quotes_trades/AAPL/2021-09-17/AAPL210917C00150000/2021-09-01.json",
+		"ticks/quotes/.meta/AAPL210917C00150000.json":             "ticks/quotes/.meta/AAPL/2021-09-17/AAPL210917C00150000.json",
+		"candles/day/AAPL210917C00150000.json":                    "candles/day/AAPL/2021-09-17/AAPL210917C00150000.json",
+		"candles/day/.meta/AAPL210917C00150000.json":              "candles/day/.meta/AAPL/2021-09-17/AAPL210917C00150000.json",
+		"ticks/quotes_trades/SPY/2021-09-01.json":                 "ticks/quotes_trades/SPY/2021-09-01.json",
+		"instruments.json": "instruments.json",
+	}
+	for old, moved := range cases {
+		assert.Equal(t, moved, groupOptionSymbolPath(old))
+		// Already migrated path stays
+		assert.Equal(t, moved, groupOptionSymbolPath(moved))
+	}
+}
+
+func writeLegacyStorage(t *testing.T, dir string) {
+	files := map[string]string{
+		"ticks/quotes_trades/AAPL210917C00150000/2021-09-01.json": `[{"Symbol":"AAPL210917C00150000"}]`,
+		"ticks/quotes_trades/.meta/AAPL210917C00150000.json":      `{"Symbol":"AAPL210917C00150000"}`,
+		"ticks/quotes_trades/SPY/2021-09-01.json":                 `[{"Symbol":"SPY"}]`,
+		"candles/day/SPY.json":                                    `[]`,
+		"instruments.json":                                        `[]`,
+	}
+	for rel, data := range files {
+		err := writeFileAtomic(path.Join(dir, rel), []byte(data), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestJsonStorage_MigrateInPlace(t *testing.T) {
+	testDir := "./test_data/migrate"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+	writeLegacyStorage(t, testDir)
+
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC, Market: WeekdaysMarket}
+	report, err := storage.Migrate("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, LegacyLayoutVersion, report.From)
+	assert.Equal(t, CurrentLayoutVersion, report.To)
+	assert.Equal(t, 2, len(report.Moved))
+	assert.Equal(t, 5, report.Files)
+	assert.False(t, fileExists(path.Join(testDir, "ticks/quotes_trades/AAPL210917C00150000")))
+
+	ticks, err := storage.GetStoredTicks("AAPL210917C00150000",
+		DateRange{timeOnTheFly(2021, 9, 1), timeOnTheFly(2021, 9, 2)}, true, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ticks))
+
+	_, err = storage.Open()
+	assert.Nil(t, err)
+
+	// Second migration has nothing To do
+	report, err = storage.Migrate("")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(report.Applied))
+}
+
+func TestJsonStorage_MigrateToNewFolder(t *testing.T) {
+	testDir := "./test_data/migrate_src"
+	dstDir := "./test_data/migrate_dst"
+	os.RemoveAll(testDir)
+	os.RemoveAll(dstDir)
+	defer os.RemoveAll(testDir)
+	defer os.RemoveAll(dstDir)
+	writeLegacyStorage(t, testDir)
+
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC, Market: WeekdaysMarket}
+	report, err := storage.Migrate(dstDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, dstDir, report.Path)
+	assert.Equal(t, 1, len(report.Applied))
+
+	// Source is untouched
+	assert.True(t, fileExists(path.Join(testDir, "ticks/quotes_trades/AAPL210917C00150000/2021-09-01.json")))
+	assert.False(t, fileExists(storage.manifestPath()))
+
+	data, err := ioutil.ReadFile(path.Join(dstDir, "ticks/quotes_trades/.meta/AAPL/2021-09-17/AAPL210917C00150000.json"))
+	assert.Nil(t, err)
+	assert.Equal(t, `{"Symbol":"AAPL210917C00150000"}`, string(data))
+
+	_, err = (&JsonStorage{Path: dstDir, TimeZone: time.UTC, Market: WeekdaysMarket}).Open()
+	assert.Nil(t, err)
+
+	// Destination must be empty
+	_, err = storage.Migrate(dstDir)
+	assert.NotNil(t, err)
+}
+
+func TestVerifyMigration(t *testing.T) {
+	testDir := "./test_data/migrate_verify"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+	writeLegacyStorage(t, testDir)
+
+	checksums, err := storageChecksums(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, verifyMigration(testDir, checksums))
+
+	ioutil.WriteFile(path.Join(testDir, "candles/day/SPY.json"), []byte(`[{}]`), 0644)
+	err = verifyMigration(testDir, checksums)
+	assert.IsType(t, &ErrMigrationVerification{}, err)
+}
@@ -0,0 +1,253 @@
+package marketdata
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+type MarketState string
+
+const (
+	NormalMarket  MarketState = "normal"
+	LockedMarket  MarketState = "locked"  // Best bid is equal To best ask
+	CrossedMarket MarketState = "crossed" // Best bid is above best ask
+)
+
+// One side of venue quote. Zero price or size means that venue has no quote on this side
+type BookSide struct {
+	Price    float64
+	Size     int64
+	Datetime time.Time
+	Seq      int64
+}
+
+func (s *BookSide) isEmpty() bool {
+	return s.Price <= 0 || s.Size <= 0
+}
+
+// Best bid and offer of single venue
+type VenueQuote struct {
+	Exch string
+	Bid  BookSide
+	Ask  BookSide
+}
+
+// Consolidated best bid and offer. Sizes are summed over all venues at the best price, BidExch and AskExch are
+// venues which reached the best price first
+type NBBO struct {
+	Symbol   string
+	Datetime time.Time
+	Seq      int64
+	BidPrice float64
+	AskPrice float64
+	BidSize  int64
+	AskSize  int64
+	BidExch  string
+	AskExch  string
+	State    MarketState
+}
+
+func (n *NBBO) String() string {
+	return fmt.Sprintf("%v,%v,%v,%v,%v,%v,%v,%v,%v", n.Datetime.UnixNano(), n.Symbol, n.BidPrice, n.BidSize, n.BidExch,
+		n.AskPrice, n.AskSize, n.AskExch, n.State)
+}
+
+func (n *NBBO) sameQuote(other *NBBO) bool {
+	return n.BidPrice == other.BidPrice && n.AskPrice == other.AskPrice && n.BidSize == other.BidSize &&
+		n.AskSize == other.AskSize && n.BidExch == other.BidExch && n.AskExch == other.AskExch
+}
+
+// Per-venue books of one symbol and consolidated quote built From them. Quote updates bid side of BidExch venue and
+// ask side of AskExch venue. If Venues is set only these venues are included, ExcludedVenues are always skipped.
+//
+// Venue is updated only by quotes which name it, so venue which left the best price without new quote keeps its
+// level. Set Consolidated for consolidated quote feeds (tick quotes of providers): quote names venues of the best
+// prices, so venues with better prices than quoted ones are cleared. MaxAge expires venue sides which weren't
+// updated for given time of quote stream.
+type NBBOBook struct {
+	Symbol         string
+	Venues         []string
+	ExcludedVenues []string
+	Consolidated   bool
+	MaxAge         time.Duration // 0 means venue sides never expire
+
+	venues map[string]*VenueQuote
+	last   *NBBO
+}
+
+func NewNBBOBook(symbol string, venues ...string) *NBBOBook {
+	return &NBBOBook{
+		Symbol: symbol,
+		Venues: venues,
+		venues: make(map[string]*VenueQuote),
+	}
+}
+
+func (b *NBBOBook) isIncluded(exch string) bool {
+	if containsString(b.ExcludedVenues, exch) {
+		return false
+	}
+	return len(b.Venues) == 0 || containsString(b.Venues, exch)
+}
+
+func (b *NBBOBook) venue(exch string) *VenueQuote {
+	v, ok := b.venues[exch]
+	if !ok {
+		v = &VenueQuote{Exch: exch}
+		b.venues[exch] = v
+	}
+	return v
+}
+
+// Applies quote To venue books. Returns new NBBO and true if consolidated quote has changed
+func (b *NBBOBook) Update(q *Quote) (*NBBO, bool) {
+	if b.venues == nil {
+		b.venues = make(map[string]*VenueQuote)
+	}
+
+	if b.Consolidated {
+		b.clearBetter(q)
+	}
+	if b.isIncluded(q.BidExch) {
+		b.venue(q.BidExch).Bid = BookSide{q.BidPrice, q.BidSize, q.Datetime, q.Seq}
+	}
+	if b.isIncluded(q.AskExch) {
+		b.venue(q.AskExch).Ask = BookSide{q.AskPrice, q.AskSize, q.Datetime, q.Seq}
+	}
+
+	nbbo := b.consolidate(q.Datetime)
+	nbbo.Datetime = q.Datetime
+	nbbo.Seq = q.Seq
+
+	if b.last != nil && b.last.sameQuote(nbbo) {
+		return b.last, false
+	}
+	b.last = nbbo
+	return nbbo, true
+}
+
+// Clears venue sides better than consolidated quote. They would be named by the quote if they were still there
+func (b *NBBOBook) clearBetter(q *Quote) {
+	for exch, v := range b.venues {
+		if exch != q.BidExch && q.BidPrice > 0 && v.Bid.Price > q.BidPrice {
+			v.Bid = BookSide{}
+		}
+		if exch != q.AskExch && q.AskPrice > 0 && !v.Ask.isEmpty() && v.Ask.Price < q.AskPrice {
+			v.Ask = BookSide{}
+		}
+	}
+}
+
+func (b *NBBOBook) isExpired(s *BookSide, now time.Time) bool {
+	return b.MaxAge > 0 && now.Sub(s.Datetime) > b.MaxAge
+}
+
+func (b *NBBOBook) consolidate(now time.Time) *NBBO {
+	nbbo := NBBO{Symbol: b.Symbol, State: NormalMarket}
+	var bidTime, askTime BookSide
+
+	for exch, v := range b.venues {
+		if b.isExpired(&v.Bid, now) {
+			v.Bid = BookSide{}
+		}
+		if b.isExpired(&v.Ask, now) {
+			v.Ask = BookSide{}
+		}
+
+		if !v.Bid.isEmpty() {
+			switch {
+			case v.Bid.Price > nbbo.BidPrice:
+				nbbo.BidPrice, nbbo.BidSize, nbbo.BidExch = v.Bid.Price, v.Bid.Size, exch
+				bidTime = v.Bid
+			case v.Bid.Price == nbbo.BidPrice:
+				nbbo.BidSize += v.Bid.Size
+				if sideBefore(&v.Bid, exch, &bidTime, nbbo.BidExch) {
+					nbbo.BidExch = exch
+					bidTime = v.Bid
+				}
+			}
+		}
+
+		if !v.Ask.isEmpty() {
+			switch {
+			case nbbo.AskPrice == 0 || v.Ask.Price < nbbo.AskPrice:
+				nbbo.AskPrice, nbbo.AskSize, nbbo.AskExch = v.Ask.Price, v.Ask.Size, exch
+				askTime = v.Ask
+			case v.Ask.Price == nbbo.AskPrice:
+				nbbo.AskSize += v.Ask.Size
+				if sideBefore(&v.Ask, exch, &askTime, nbbo.AskExch) {
+					nbbo.AskExch = exch
+					askTime = v.Ask
+				}
+			}
+		}
+	}
+
+	if nbbo.BidPrice > 0 && nbbo.AskPrice > 0 {
+		if nbbo.BidPrice == nbbo.AskPrice {
+			nbbo.State = LockedMarket
+		} else if nbbo.BidPrice > nbbo.AskPrice {
+			nbbo.State = CrossedMarket
+		}
+	}
+
+	return &nbbo
+}
+
+// Time priority of venues at the same price. Venue name breaks the tie To keep result independent of map order
+func sideBefore(a *BookSide, aExch string, b *BookSide, bExch string) bool {
+	if !a.Datetime.Equal(b.Datetime) {
+		return a.Datetime.Before(b.Datetime)
+	}
+	if a.Seq != b.Seq {
+		return a.Seq < b.Seq
+	}
+	return aExch < bExch
+}
+
+// Current consolidated quote. Nil until the first update
+func (b *NBBOBook) NBBO() *NBBO {
+	return b.last
+}
+
+func (b *NBBOBook) Venue(exch string) (VenueQuote, bool) {
+	v, ok := b.venues[exch]
+	if !ok {
+		return VenueQuote{}, false
+	}
+	return *v, true
+}
+
+// Quotes of all venues sorted by venue
+func (b *NBBOBook) VenueQuotes() []VenueQuote {
+	var quotes []VenueQuote
+	for _, v := range b.venues {
+		quotes = append(quotes, *v)
+	}
+	sort.Slice(quotes, func(i, j int) bool {
+		return quotes[i].Exch < quotes[j].Exch
+	})
+	return quotes
+}
+
+// Replays quotes of ticks through the book and returns NBBO change events. Trades are skipped
+func (b *NBBOBook) UpdateTicks(ticks TickArray) []*NBBO {
+	var changes []*NBBO
+	for _, t := range ticks {
+		if !t.isQuoteEvent() {
+			continue
+		}
+		if nbbo, changed := b.Update(t.Quote()); changed {
+			changes = append(changes, nbbo)
+		}
+	}
+	return changes
+}
+
+// NBBO change events of consolidated tick stream. Only given venues are included if they are set
+func BuildNBBO(symbol string, ticks TickArray, venues ...string) []*NBBO {
+	book := NewNBBOBook(symbol, venues...)
+	book.Consolidated = true
+	return book.UpdateTicks(ticks)
+}
@@ -0,0 +1,115 @@
+package marketdata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func quoteMock(seq int64, bid float64, bidSize int64, bidExch string, ask float64, askSize int64, askExch string) *Quote {
+	dt := time.Date(2018, 11, 5, 14, 30, 0, 0, time.UTC)
+	return &Quote{Symbol: "SPY", Datetime: dt.Add(time.Duration(seq) * time.Second), Seq: seq, BidPrice: bid,
+		AskPrice: ask, BidSize: bidSize, AskSize: askSize, BidExch: bidExch, AskExch: askExch}
+}
+
+func TestNBBOBook_Update(t *testing.T) {
+	book := NewNBBOBook("SPY")
+
+	nbbo, changed := book.Update(quoteMock(0, 10.00, 1, "P", 10.02, 2, "Q"))
+	assert.True(t, changed)
+	assert.Equal(t, 10.00, nbbo.BidPrice)
+	assert.Equal(t, 10.02, nbbo.AskPrice)
+	assert.Equal(t, NormalMarket, nbbo.State)
+
+	// Second venue joins the bid. Size is summed, first venue keeps time priority
+	nbbo, changed = book.Update(quoteMock(1, 10.00, 3, "Z", 10.03, 1, "Z"))
+	assert.True(t, changed)
+	assert.Equal(t, int64(4), nbbo.BidSize)
+	assert.Equal(t, "P", nbbo.BidExch)
+	assert.Equal(t, 10.02, nbbo.AskPrice)
+	assert.Equal(t, "Q", nbbo.AskExch)
+
+	// Worse quote of other venue doesn't change NBBO
+	_, changed = book.Update(quoteMock(2, 9.99, 1, "K", 10.05, 1, "K"))
+	assert.False(t, changed)
+
+	venue, ok := book.Venue("Z")
+	assert.True(t, ok)
+	assert.Equal(t, 10.03, venue.Ask.Price)
+	assert.Equal(t, 4, len(book.VenueQuotes()))
+
+	// Locked and crossed markets
+	nbbo, _ = book.Update(quoteMock(3, 10.02, 1, "K", 10.05, 1, "K"))
+	assert.Equal(t, LockedMarket, nbbo.State)
+	nbbo, _ = book.Update(quoteMock(4, 10.03, 1, "K", 10.05, 1, "K"))
+	assert.Equal(t, CrossedMarket, nbbo.State)
+	assert.Equal(t, "K", nbbo.BidExch)
+
+	// Venue withdraws its bid
+	nbbo, _ = book.Update(quoteMock(5, 0, 0, "K", 10.05, 1, "K"))
+	assert.Equal(t, NormalMarket, nbbo.State)
+	assert.Equal(t, 10.00, nbbo.BidPrice)
+}
+
+func TestBuildNBBO_Venues(t *testing.T) {
+	ticks := TickArray{
+		quoteMock(0, 10.00, 1, "P", 10.02, 2, "P").Tick(),
+		quoteMock(1, 10.01, 1, "D", 10.01, 1, "D").Tick(),
+		quoteMock(2, 10.00, 5, "Q", 10.02, 2, "Q").Tick(),
+	}
+	ticks = append(ticks, (&Trade{Symbol: "SPY", Price: 10.01, Size: 100}).Tick())
+
+	// D (FINRA ADF) is not included, so market is never locked
+	changes := BuildNBBO("SPY", ticks, "P", "Q")
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, int64(6), changes[1].BidSize)
+	for _, c := range changes {
+		assert.Equal(t, NormalMarket, c.State)
+	}
+
+	book := NewNBBOBook("SPY")
+	book.ExcludedVenues = []string{"Q"}
+	changes = book.UpdateTicks(ticks)
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, LockedMarket, changes[1].State)
+}
+
+func TestNBBOBook_StaleVenues(t *testing.T) {
+	quotes := []*Quote{
+		quoteMock(0, 10.00, 1, "P", 10.02, 1, "Q"),
+		// Market moves down. P isn't named anymore, its bid is gone
+		quoteMock(1, 9.98, 1, "Z", 10.00, 1, "Q"),
+	}
+
+	// Venue feed keeps P level and reports locked market
+	book := NewNBBOBook("SPY")
+	for _, q := range quotes {
+		book.Update(q)
+	}
+	assert.Equal(t, LockedMarket, book.NBBO().State)
+
+	book = NewNBBOBook("SPY")
+	book.Consolidated = true
+	for _, q := range quotes {
+		book.Update(q)
+	}
+	nbbo := book.NBBO()
+	assert.Equal(t, NormalMarket, nbbo.State)
+	assert.Equal(t, 9.98, nbbo.BidPrice)
+	assert.Equal(t, "Z", nbbo.BidExch)
+	venue, _ := book.Venue("P")
+	assert.True(t, venue.Bid.isEmpty())
+
+	// Venue sides expire after MaxAge
+	book = NewNBBOBook("SPY")
+	book.MaxAge = 1500 * time.Millisecond
+	book.Update(quoteMock(0, 10.00, 1, "P", 10.05, 1, "P"))
+	book.Update(quoteMock(1, 9.99, 1, "Q", 10.04, 1, "Q"))
+	nbbo, _ = book.Update(quoteMock(2, 9.98, 1, "Z", 10.03, 1, "Z"))
+	assert.Equal(t, 9.99, nbbo.BidPrice)
+	assert.Equal(t, "Q", nbbo.BidExch)
+	assert.Equal(t, 10.03, nbbo.AskPrice)
+	_, ok := book.Venue("P")
+	assert.True(t, ok)
+}
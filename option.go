@@ -0,0 +1,126 @@
+package marketdata
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	occDateLayout   = "060102"
+	optionDirLayout = "2006-01-02"
+)
+
+type OptionRight string
+
+const (
+	Call OptionRight = "C"
+	Put  OptionRight = "P"
+)
+
+type ErrParsingOptionSymbol struct {
+	symbol string
+}
+
+func (e *ErrParsingOptionSymbol) Error() string {
+	return fmt.Sprintf("Can't parse option symbol: %q", e.symbol)
+}
+
+type OptionContract struct {
+	Underlying string
+	Expiry     time.Time
+	Strike     float64
+	Right      OptionRight
+}
+
+// Compact OCC symbol without padding: AAPL210917C00150000. It's used as canonical option symbol in storage
+// and providers.
+func (o *OptionContract) Symbol() string {
+	return strings.ToUpper(o.Underlying) + o.occSuffix()
+}
+
+// OCC symbol padded To 21 characters: "AAPL  210917C00150000"
+func (o *OptionContract) OCCSymbol() string {
+	return fmt.Sprintf("%-6v", strings.ToUpper(o.Underlying)) + o.occSuffix()
+}
+
+func (o *OptionContract) occSuffix() string {
+	strike := int64(math.Round(o.Strike * 1000))
+	return fmt.Sprintf("%v%v%08d", o.Expiry.Format(occDateLayout), o.Right, strike)
+}
+
+func (o *OptionContract) String() string {
+	return fmt.Sprintf("%v %v %v %v", o.Underlying, o.Expiry.Format(optionDirLayout), o.Strike, o.Right)
+}
+
+// Parses padded or compact OCC symbol
+func ParseOCCSymbol(symbol string) (*OptionContract, error) {
+	s := strings.TrimSpace(symbol)
+	// Root, 6 digits of date, right and 8 digits of strike
+	if len(s) < 16 {
+		return nil, &ErrParsingOptionSymbol{symbol}
+	}
+
+	suffix := s[len(s)-15:]
+	root := strings.TrimSpace(s[:len(s)-15])
+	if root == "" || len(root) > 6 || strings.Contains(root, " ") {
+		return nil, &ErrParsingOptionSymbol{symbol}
+	}
+
+	expiry, err := time.Parse(occDateLayout, suffix[:6])
+	if err != nil {
+		return nil, &ErrParsingOptionSymbol{symbol}
+	}
+
+	right := OptionRight(suffix[6:7])
+	if right != Call && right != Put {
+		return nil, &ErrParsingOptionSymbol{symbol}
+	}
+
+	strike, err := strconv.ParseInt(suffix[7:], 10, 64)
+	if err != nil || strike < 0 {
+		return nil, &ErrParsingOptionSymbol{symbol}
+	}
+
+	contract := OptionContract{
+		Underlying: root,
+		Expiry:     expiry,
+		Strike:     float64(strike) / 1000,
+		Right:      right,
+	}
+
+	return &contract, nil
+}
+
+func isOptionSymbol(symbol string) bool {
+	_, err := ParseOCCSymbol(symbol)
+	return err == nil
+}
+
+// Relative storage path of symbol. Options are grouped by underlying and expiry:
+// AAPL/2021-09-17/AAPL210917C00150000. Other symbols are stored as is.
+func symbolStoragePath(symbol string) string {
+	contract, err := ParseOCCSymbol(symbol)
+	if err != nil {
+		return symbol
+	}
+	return path.Join(contract.Underlying, contract.Expiry.Format(optionDirLayout), contract.Symbol())
+}
+
+// Sorts contracts by expiry, strike and right
+func sortOptionContracts(contracts []*OptionContract) {
+	sort.SliceStable(contracts, func(i, j int) bool {
+		a, b := contracts[i], contracts[j]
+		if !a.Expiry.Equal(b.Expiry) {
+			return a.Expiry.Before(b.Expiry)
+		}
+		if a.Strike != b.Strike {
+			return a.Strike < b.Strike
+		}
+		return a.Right < b.Right
+	})
+}
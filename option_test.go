@@ -0,0 +1,64 @@
+package marketdata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOCCSymbol(t *testing.T) {
+	contract, err := ParseOCCSymbol("AAPL  210917C00150000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := OptionContract{"AAPL", timeOnTheFly(2021, 9, 17), 150, Call}
+	assert.Equal(t, expected, *contract)
+	assert.Equal(t, "AAPL  210917C00150000", contract.OCCSymbol())
+	assert.Equal(t, "AAPL210917C00150000", contract.Symbol())
+
+	contract, err = ParseOCCSymbol("SPY181116P00272500")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 272.5, contract.Strike)
+	assert.Equal(t, Put, contract.Right)
+
+	for _, s := range []string{"SPY", "AAPL  210917X00150000", "AAPL  211317C00150000", "TOOLONGROOT210917C00150000"} {
+		_, err = ParseOCCSymbol(s)
+		assert.IsType(t, &ErrParsingOptionSymbol{}, err, s)
+	}
+}
+
+func TestSymbolStoragePath(t *testing.T) {
+	assert.Equal(t, "SPY", symbolStoragePath("SPY"))
+	assert.Equal(t, "AAPL/2021-09-17/AAPL210917C00150000", symbolStoragePath("AAPL210917C00150000"))
+}
+
+func TestActiveTick_parseOptionChain(t *testing.T) {
+	raw := ".MSFT  181116P00105000\r\n.MSFT  181116C00105000\r\n.MSFT  181109C00110000\r\n"
+
+	contracts, err := parseOptionChain(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 3, len(contracts))
+	assert.Equal(t, "MSFT181109C00110000", contracts[0].Symbol())
+	assert.Equal(t, Call, contracts[1].Right)
+	assert.Equal(t, Put, contracts[2].Right)
+
+	at := NewActiveTick(5000, "127.0.0.1", 2, "")
+	assert.Equal(t, ".MSFT  181116P00105000", at.vendorSymbol(contracts[2].Symbol()))
+}
+
+func TestActiveTick_parseOptionCandles(t *testing.T) {
+	raw := "20181101000000,1.500000,1.700000,1.400000,1.650000,120,3400\r\n"
+
+	candles, err := parseToCandlesList(raw, "MSFT181116C00105000", noDecimals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(3400), candles[0].OpenInterest)
+}
@@ -0,0 +1,465 @@
+package marketdata
+
+import (
+	"container/heap"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type ReplayEventType int
+
+const (
+	TickReplayEvent     ReplayEventType = iota
+	CandleReplayEvent                   // Stored candle. It's replayed at its close, so it doesn't look ahead
+	BarCloseReplayEvent                 // Bar aggregated From replayed trades is closed
+)
+
+type ReplayEvent struct {
+	Type     ReplayEventType
+	Symbol   string
+	Datetime time.Time // Time of event in replayed stream. Close time for candles and bars
+	Tick     *Tick
+	Candle   *Candle // Stored candle or closed bar
+}
+
+type ReplayOptions struct {
+	Symbols   []string
+	Range     DateRange
+	Quotes    bool
+	Trades    bool
+	TimeFrame string        // Stored candles To replay. No candles are replayed if it's empty
+	BarPeriod time.Duration // Bars of this period are aggregated From trades. 0 means no bars
+	Speed     float64       // Replay speed relative To real time. 0 means as fast as possible
+}
+
+// Replays stored ticks and candles of several symbols as one stream ordered by time. Ticks are read From storage
+// day by day for every symbol and merged lazily, so memory doesn't grow with replayed range. Events with the same
+// time are ordered by symbol position in options.
+//
+//	engine, _ := NewReplayEngine(storage, ReplayOptions{Symbols: []string{"SPY", "QQQ"}, Range: rng, Trades: true})
+//	defer engine.Close()
+//	for engine.Next() {
+//		e := engine.Event()
+//		...
+//	}
+//	err := engine.Err()
+//
+// Pause, Resume, Seek and Close can be called From other goroutines.
+type ReplayEngine struct {
+	Storage Storage
+	Options ReplayOptions
+
+	sources replayHeap
+	bars    []*barBuilder // By symbol position
+	closed  []*ReplayEvent
+	event   *ReplayEvent
+	err     error
+
+	// Pacing anchor: replayed time simStart corresponds To wall time wallStart
+	simStart  time.Time
+	wallStart time.Time
+
+	mu      sync.Mutex
+	resumed chan struct{} // Closed when engine isn't paused
+	control chan struct{} // Wakes up paced wait on pause, seek and close
+	seekTo  *time.Time
+	done    bool
+}
+
+func NewReplayEngine(storage Storage, options ReplayOptions) (*ReplayEngine, error) {
+	if len(options.Symbols) == 0 {
+		return nil, errors.New("No symbols To replay")
+	}
+	if !options.Quotes && !options.Trades && options.TimeFrame == "" {
+		return nil, errors.New("Nothing To replay: set quotes, trades or timeframe")
+	}
+	if options.BarPeriod > 0 && !options.Trades {
+		return nil, errors.New("Bars are aggregated From trades, trades should be replayed")
+	}
+	if options.Speed < 0 {
+		return nil, errors.Errorf("Wrong replay speed %v", options.Speed)
+	}
+	if options.TimeFrame != "" {
+		if _, err := timeFrameEnd(time.Time{}, options.TimeFrame); err != nil {
+			return nil, err
+		}
+	}
+
+	resumed := make(chan struct{})
+	close(resumed)
+	e := ReplayEngine{
+		Storage: storage,
+		Options: options,
+		resumed: resumed,
+		control: make(chan struct{}, 1),
+	}
+	err := e.start(options.Range.From)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// (Re)creates symbol sources positioned at the first event not before from
+func (e *ReplayEngine) start(from time.Time) error {
+	e.sources = e.sources[:0]
+	e.bars = make([]*barBuilder, len(e.Options.Symbols))
+	e.closed = nil
+	e.simStart = time.Time{}
+
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	if day.Before(e.Options.Range.From) {
+		day = e.Options.Range.From
+	}
+	for i, symbol := range e.Options.Symbols {
+		if e.Options.BarPeriod > 0 {
+			e.bars[i] = &barBuilder{period: e.Options.BarPeriod}
+		}
+
+		var sources []replaySource
+		if e.Options.Quotes || e.Options.Trades {
+			it := NewTickIterator(e.Storage, symbol, DateRange{day, e.Options.Range.To}, e.Options.Quotes,
+				e.Options.Trades, IteratorOptions{})
+			sources = append(sources, &tickSource{it})
+		}
+		if e.Options.TimeFrame != "" {
+			it := NewCandleIterator(e.Storage, symbol, e.Options.TimeFrame, e.Options.Range, IteratorOptions{})
+			sources = append(sources, &candleSource{it})
+		}
+
+		for _, s := range sources {
+			ev, err := skipReplayBefore(s, from)
+			if err != nil {
+				return errors.Wrapf(err, "replay Symbol: %v", symbol)
+			}
+			if ev != nil {
+				e.sources = append(e.sources, &replayItem{source: s, event: ev, order: i})
+			}
+		}
+	}
+	heap.Init(&e.sources)
+	return nil
+}
+
+// Advances To the next event. Blocks while engine is paused and paces events when speed is set. Returns false when
+// replay is over, closed or failed, Err tells which
+func (e *ReplayEngine) Next() bool {
+	for {
+		if !e.waitResumed() {
+			return false
+		}
+
+		e.mu.Lock()
+		seekTo := e.seekTo
+		e.seekTo = nil
+		e.mu.Unlock()
+		if seekTo != nil {
+			e.err = e.start(*seekTo)
+			if e.err != nil {
+				return false
+			}
+		}
+
+		ev, ok := e.nextEvent()
+		if e.err != nil || !ok {
+			return false
+		}
+		if !e.pace(ev.Datetime) {
+			// Paused, sought or closed while waiting. Event is emitted after resume unless position changed
+			e.mu.Lock()
+			sought := e.seekTo != nil
+			e.mu.Unlock()
+			if !sought {
+				e.closed = append([]*ReplayEvent{ev}, e.closed...)
+			}
+			continue
+		}
+		e.event = ev
+		return true
+	}
+}
+
+// Current event. It's valid after Next returned true
+func (e *ReplayEngine) Event() *ReplayEvent {
+	return e.event
+}
+
+func (e *ReplayEngine) Err() error {
+	return e.err
+}
+
+// Stops replay. Blocked Next returns false
+func (e *ReplayEngine) Close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.done {
+		return
+	}
+	e.done = true
+	e.wake()
+	select {
+	case <-e.resumed:
+	default:
+		close(e.resumed)
+	}
+}
+
+func (e *ReplayEngine) Pause() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	select {
+	case <-e.resumed:
+		if !e.done {
+			e.resumed = make(chan struct{})
+			e.wake()
+		}
+	default:
+	}
+}
+
+func (e *ReplayEngine) Resume() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	select {
+	case <-e.resumed:
+	default:
+		close(e.resumed)
+	}
+}
+
+func (e *ReplayEngine) Paused() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	select {
+	case <-e.resumed:
+		return false
+	default:
+		return !e.done
+	}
+}
+
+// Moves replay To the first event at or after t. Open bars are dropped
+func (e *ReplayEngine) Seek(t time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.seekTo = &t
+	e.wake()
+}
+
+// Should be called with mutex held
+func (e *ReplayEngine) wake() {
+	select {
+	case e.control <- struct{}{}:
+	default:
+	}
+}
+
+func (e *ReplayEngine) waitResumed() bool {
+	for {
+		e.mu.Lock()
+		resumed, done := e.resumed, e.done
+		e.mu.Unlock()
+		if done {
+			return false
+		}
+
+		select {
+		case <-resumed:
+			e.mu.Lock()
+			done = e.done
+			e.mu.Unlock()
+			return !done
+		case <-e.control:
+			// Seek while paused is applied after resume
+		}
+	}
+}
+
+// Waits until event time is due according To speed. Returns false if wait was interrupted
+func (e *ReplayEngine) pace(t time.Time) bool {
+	if e.Options.Speed == 0 {
+		return true
+	}
+	if e.simStart.IsZero() {
+		e.simStart, e.wallStart = t, time.Now()
+		return true
+	}
+
+	due := e.wallStart.Add(time.Duration(float64(t.Sub(e.simStart)) / e.Options.Speed))
+	wait := time.Until(due)
+	if wait <= 0 {
+		return true
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-e.control:
+		// Speed is measured From the first event after pause or seek
+		e.simStart = time.Time{}
+		return false
+	}
+}
+
+// Takes the next event of merged stream. Bars which close not later than it go first
+func (e *ReplayEngine) nextEvent() (*ReplayEvent, bool) {
+	if len(e.closed) > 0 {
+		ev := e.closed[0]
+		e.closed = e.closed[1:]
+		return ev, true
+	}
+
+	if len(e.sources) == 0 {
+		e.closeBars(nil)
+		if len(e.closed) == 0 {
+			return nil, false
+		}
+		return e.nextEvent()
+	}
+
+	item := e.sources[0]
+	ev := item.event
+	if e.closeBars(&ev.Datetime); len(e.closed) > 0 {
+		return e.nextEvent()
+	}
+
+	next, err := item.source.next()
+	if err != nil {
+		e.err = errors.Wrapf(err, "replay Symbol: %v", ev.Symbol)
+		return nil, false
+	}
+	if next == nil {
+		heap.Pop(&e.sources)
+	} else {
+		item.event = next
+		heap.Fix(&e.sources, 0)
+	}
+
+	if ev.Tick != nil && ev.Tick.HasTrade() && e.bars[item.order] != nil {
+		e.bars[item.order].add(ev.Tick)
+	}
+	return ev, true
+}
+
+// Moves bars closed by time t To closed queue. All bars are closed if t is nil
+func (e *ReplayEngine) closeBars(t *time.Time) {
+	for i, b := range e.bars {
+		if b == nil || b.current == nil {
+			continue
+		}
+		end := b.current.Datetime.Add(b.period)
+		if t != nil && end.After(*t) {
+			continue
+		}
+		if bar := b.complete(); bar != nil {
+			e.closed = append(e.closed, &ReplayEvent{Type: BarCloseReplayEvent, Symbol: e.Options.Symbols[i],
+				Datetime: end, Candle: bar})
+		}
+	}
+
+	// Bars of different periods don't exist, but symbols are visited in order, so sort by time is stable
+	for i := 1; i < len(e.closed); i++ {
+		for j := i; j > 0 && e.closed[j].Datetime.Before(e.closed[j-1].Datetime); j-- {
+			e.closed[j], e.closed[j-1] = e.closed[j-1], e.closed[j]
+		}
+	}
+}
+
+// Close time of candle of timeframe started at t
+func timeFrameEnd(t time.Time, timeframe string) (time.Time, error) {
+	switch timeframe {
+	case "D":
+		return t.AddDate(0, 0, 1), nil
+	case "W":
+		return t.AddDate(0, 0, 7), nil
+	}
+	minutes, err := strconv.Atoi(timeframe)
+	if err != nil || minutes <= 0 {
+		return t, errors.Errorf("Wrong timeframe %v", timeframe)
+	}
+	return t.Add(time.Duration(minutes) * time.Minute), nil
+}
+
+// Lazy stream of symbol events ordered by time
+type replaySource interface {
+	// Returns the next event or nil when source is over
+	next() (*ReplayEvent, error)
+}
+
+// Returns the first event of source not before t
+func skipReplayBefore(s replaySource, t time.Time) (*ReplayEvent, error) {
+	for {
+		ev, err := s.next()
+		if ev == nil || err != nil || !ev.Datetime.Before(t) {
+			return ev, err
+		}
+	}
+}
+
+type tickSource struct {
+	it *TickIterator
+}
+
+func (s *tickSource) next() (*ReplayEvent, error) {
+	if !s.it.Next() {
+		return nil, s.it.Err()
+	}
+	t := s.it.Tick()
+	return &ReplayEvent{Type: TickReplayEvent, Symbol: s.it.Symbol, Datetime: t.Datetime, Tick: t}, nil
+}
+
+type candleSource struct {
+	it *CandleIterator
+}
+
+func (s *candleSource) next() (*ReplayEvent, error) {
+	if !s.it.Next() {
+		return nil, s.it.Err()
+	}
+	c := s.it.Candle()
+	end, err := timeFrameEnd(c.Datetime, s.it.TimeFrame)
+	if err != nil {
+		return nil, err
+	}
+	return &ReplayEvent{Type: CandleReplayEvent, Symbol: s.it.Symbol, Datetime: end, Candle: c}, nil
+}
+
+type replayItem struct {
+	source replaySource
+	event  *ReplayEvent
+	order  int // Symbol position. Orders events with the same time
+}
+
+// Min-heap of sources by time of their current events
+type replayHeap []*replayItem
+
+func (h replayHeap) Len() int { return len(h) }
+
+func (h replayHeap) Less(i, j int) bool {
+	a, b := h[i].event, h[j].event
+	if !a.Datetime.Equal(b.Datetime) {
+		return a.Datetime.Before(b.Datetime)
+	}
+	if h[i].order != h[j].order {
+		return h[i].order < h[j].order
+	}
+	// Candle of the same symbol closes after ticks of its last moment
+	return a.Type < b.Type
+}
+
+func (h replayHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *replayHeap) Push(x interface{}) {
+	*h = append(*h, x.(*replayItem))
+}
+
+func (h *replayHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
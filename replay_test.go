@@ -0,0 +1,218 @@
+package marketdata
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Serves ticks and daily candles From memory and records requested tick days
+type replayStorageMock struct {
+	ticks   map[string]TickArray
+	candles map[string]CandleArray
+
+	mu        sync.Mutex
+	requested []time.Time
+}
+
+func (s *replayStorageMock) GetStoredCandles(symbol string, tf string, dRange DateRange) (CandleArray, error) {
+	return s.candles[symbol], nil
+}
+
+func (s *replayStorageMock) GetStoredTicks(symbol string, dRange DateRange, quotes bool,
+	trades bool) (TickArray, error) {
+
+	s.mu.Lock()
+	s.requested = append(s.requested, dRange.From)
+	s.mu.Unlock()
+
+	var ticks TickArray
+	for _, t := range s.ticks[symbol] {
+		if t.Datetime.Before(dRange.From) || !t.Datetime.Before(dRange.To.AddDate(0, 0, 1)) {
+			continue
+		}
+		if (trades && t.HasTrade()) || (quotes && t.isQuoteEvent()) {
+			ticks = append(ticks, t)
+		}
+	}
+	return ticks, nil
+}
+
+func symbolTrade(symbol string, day int, seconds int, price float64) *Tick {
+	t := tradeAt(0, price)
+	t.Symbol = symbol
+	t.Datetime = t.Datetime.AddDate(0, 0, day).Add(time.Duration(seconds) * time.Second)
+	return t
+}
+
+func replayAll(t *testing.T, e *ReplayEngine) []*ReplayEvent {
+	var events []*ReplayEvent
+	for e.Next() {
+		events = append(events, e.Event())
+	}
+	assert.Nil(t, e.Err())
+	return events
+}
+
+func TestReplayEngine_Merge(t *testing.T) {
+	storage := &replayStorageMock{ticks: map[string]TickArray{
+		"SPY": {symbolTrade("SPY", 0, 0, 10), symbolTrade("SPY", 0, 90, 11), symbolTrade("SPY", 1, 0, 12)},
+		"QQQ": {symbolTrade("QQQ", 0, 30, 20), symbolTrade("QQQ", 0, 120, 21), symbolTrade("QQQ", 1, 0, 22)},
+	}}
+
+	e, err := NewReplayEngine(storage, ReplayOptions{
+		Symbols:   []string{"SPY", "QQQ"},
+		Range:     DateRange{timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 2)},
+		Trades:    true,
+		BarPeriod: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	// The first event is read after one day of every symbol
+	assert.True(t, e.Next())
+	assert.Equal(t, 2, len(storage.requested))
+
+	events := append([]*ReplayEvent{e.Event()}, replayAll(t, e)...)
+	var got []string
+	for _, ev := range events {
+		kind := "tick"
+		if ev.Type == BarCloseReplayEvent {
+			kind = "bar"
+		}
+		got = append(got, ev.Datetime.Format("02 15:04:05")+" "+ev.Symbol+" "+kind)
+	}
+	assert.Equal(t, []string{
+		"01 09:30:00 SPY tick",
+		"01 09:30:30 QQQ tick",
+		"01 09:31:00 SPY bar",
+		"01 09:31:00 QQQ bar",
+		"01 09:31:30 SPY tick",
+		"01 09:32:00 SPY bar",
+		"01 09:32:00 QQQ tick",
+		"01 09:33:00 QQQ bar",
+		"02 09:30:00 SPY tick",
+		"02 09:30:00 QQQ tick",
+		"02 09:31:00 SPY bar",
+		"02 09:31:00 QQQ bar",
+	}, got)
+
+	bar := events[2].Candle
+	assert.Equal(t, 10.0, bar.Close)
+	assert.Equal(t, int64(100), bar.Volume)
+	assert.Equal(t, tradeAt(0, 0).Datetime, bar.Datetime)
+}
+
+func TestReplayEngine_Candles(t *testing.T) {
+	day := timeOnTheFly(2018, 11, 1)
+	storage := &replayStorageMock{
+		ticks: map[string]TickArray{"SPY": {symbolTrade("SPY", 0, 0, 10), symbolTrade("SPY", 1, 0, 12)}},
+		candles: map[string]CandleArray{"SPY": {
+			{Symbol: "SPY", Close: 11, Datetime: day},
+			{Symbol: "SPY", Close: 12, Datetime: day.AddDate(0, 0, 1)},
+			{Symbol: "SPY", Close: 13, Datetime: day.AddDate(0, 0, 2)},
+		}},
+	}
+
+	e, err := NewReplayEngine(storage, ReplayOptions{
+		Symbols:   []string{"SPY"},
+		Range:     DateRange{day, day.AddDate(0, 0, 1)},
+		Trades:    true,
+		TimeFrame: "D",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := replayAll(t, e)
+
+	// Daily candle comes after ticks of its day
+	assert.Equal(t, 4, len(events))
+	types := []ReplayEventType{TickReplayEvent, CandleReplayEvent, TickReplayEvent, CandleReplayEvent}
+	for i, ev := range events {
+		assert.Equal(t, types[i], ev.Type)
+	}
+	assert.Equal(t, 11.0, events[1].Candle.Close)
+	assert.Equal(t, day.AddDate(0, 0, 1), events[1].Datetime)
+
+	_, err = NewReplayEngine(storage, ReplayOptions{Symbols: []string{"SPY"}, TimeFrame: "X"})
+	assert.NotNil(t, err)
+	_, err = NewReplayEngine(storage, ReplayOptions{Symbols: []string{"SPY"}, Quotes: true, BarPeriod: time.Minute})
+	assert.NotNil(t, err)
+}
+
+func TestReplayEngine_Seek(t *testing.T) {
+	storage := &replayStorageMock{ticks: map[string]TickArray{
+		"SPY": {symbolTrade("SPY", 0, 0, 10), symbolTrade("SPY", 1, 0, 11), symbolTrade("SPY", 1, 60, 12),
+			symbolTrade("SPY", 2, 0, 13)},
+	}}
+	e, err := NewReplayEngine(storage, ReplayOptions{
+		Symbols: []string{"SPY"},
+		Range:   DateRange{timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 3)},
+		Trades:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, e.Next())
+	assert.Equal(t, 10.0, e.Event().Tick.LastPrice)
+
+	e.Seek(symbolTrade("SPY", 1, 30, 0).Datetime)
+	events := replayAll(t, e)
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, 12.0, events[0].Tick.LastPrice)
+
+	// Backwards
+	e.Seek(timeOnTheFly(2018, 11, 1))
+	events = replayAll(t, e)
+	assert.Equal(t, 4, len(events))
+}
+
+func TestReplayEngine_PaceAndPause(t *testing.T) {
+	storage := &replayStorageMock{ticks: map[string]TickArray{
+		"SPY": {symbolTrade("SPY", 0, 0, 10), symbolTrade("SPY", 0, 60, 11), symbolTrade("SPY", 0, 120, 12)},
+	}}
+	e, err := NewReplayEngine(storage, ReplayOptions{
+		Symbols: []string{"SPY"},
+		Range:   DateRange{timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 1)},
+		Trades:  true,
+		Speed:   1200, // Minute in 50ms
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	assert.True(t, e.Next())
+	assert.True(t, e.Next())
+	assert.True(t, time.Since(start) >= 40*time.Millisecond)
+
+	e.Pause()
+	assert.True(t, e.Paused())
+	next := make(chan bool)
+	go func() {
+		next <- e.Next()
+	}()
+
+	select {
+	case <-next:
+		t.Fatal("Next isn't blocked by pause")
+	case <-time.After(100 * time.Millisecond):
+	}
+	e.Resume()
+	assert.True(t, <-next)
+	assert.Equal(t, 12.0, e.Event().Tick.LastPrice)
+
+	e.Pause()
+	go func() {
+		next <- e.Next()
+	}()
+	e.Close()
+	assert.False(t, <-next)
+	assert.False(t, e.Paused())
+	assert.Nil(t, e.Err())
+}
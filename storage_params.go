@@ -21,6 +21,27 @@ type TickUpdateParams struct {
 	EndTime TimeOfDay
 	Quotes   bool
 	Trades   bool
+	Mode     UpdateMode           // Fail-fast by default
+	Progress func(UpdateProgress) // Optional. Called after every processed day From the calling goroutine
+}
+
+type UpdateMode int
+
+const (
+	// The first failed day stops update and its error is returned
+	FailFastUpdate UpdateMode = iota
+	// All days are processed, errors of failed days are returned together in ErrTickUpdateFailed
+	BestEffortUpdate
+)
+
+type UpdateProgress struct {
+	Symbol  string
+	Done    int // Processed days including failed ones
+	Failed  int
+	Total   int
+	Bytes   int64 // Size of saved files
+	Elapsed time.Duration
+	ETA     time.Duration
 }
 
 func (p *TickUpdateParams) checkErrors() error {
@@ -41,14 +62,19 @@ func (p *TickUpdateParams) checkErrors() error {
 }
 
 func (p *TickUpdateParams) modifyTimes(loc *time.Location) error {
-	p.FromDate = time.Date(p.FromDate.Year(), p.FromDate.Month(), p.FromDate.Day(), 0, 0, 0, 0, loc)
-	p.ToDate = time.Date(p.ToDate.Year(), p.ToDate.Month(), p.ToDate.Day(), 0, 0, 0, 0, loc)
-
 	yest := time.Now().In(loc).AddDate(0, 0, -1)
 	yest = setTimeToSOD(yest)
 
-	if p.ToDate.After(yest) {
-		p.ToDate = yest
+	return p.modifyTimesUntil(loc, yest)
+}
+
+// The same as modifyTimes but range is cut by given last date instead of yesterday
+func (p *TickUpdateParams) modifyTimesUntil(loc *time.Location, last time.Time) error {
+	p.FromDate = time.Date(p.FromDate.Year(), p.FromDate.Month(), p.FromDate.Day(), 0, 0, 0, 0, loc)
+	p.ToDate = time.Date(p.ToDate.Year(), p.ToDate.Month(), p.ToDate.Day(), 0, 0, 0, 0, loc)
+
+	if p.ToDate.After(last) {
+		p.ToDate = last
 	}
 
 	if p.FromDate.After(p.ToDate) {
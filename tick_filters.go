@@ -0,0 +1,307 @@
+package marketdata
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Tick filter removes bad ticks. Ticks are passed in time order, kept ticks are returned in the same order
+type TickFilter interface {
+	Name() string
+	Apply(ticks TickArray) (kept TickArray, removed TickArray)
+}
+
+// Ticks removed by single filter
+type FilterResult struct {
+	Filter  string
+	Removed TickArray
+}
+
+type FilterReport struct {
+	Symbol  string
+	Input   int
+	Output  int
+	Results []FilterResult
+}
+
+func (r *FilterReport) RemovedCount() int {
+	return r.Input - r.Output
+}
+
+func (r *FilterReport) String() string {
+	str := fmt.Sprintf("%v: %v of %v ticks removed", r.Symbol, r.RemovedCount(), r.Input)
+	for _, res := range r.Results {
+		str += fmt.Sprintf(", %v: %v", res.Filter, len(res.Removed))
+	}
+	return str
+}
+
+// Filters are applied one by one in given order. OnReport is called after every Apply if it's set
+type TickFilterPipeline struct {
+	Filters  []TickFilter
+	OnReport func(report *FilterReport)
+}
+
+func NewTickFilterPipeline(filters ...TickFilter) *TickFilterPipeline {
+	return &TickFilterPipeline{Filters: filters}
+}
+
+// Default pipeline for US equities. Session is 9:30 - 16:00 in tick time
+func DefaultTickFilterPipeline() *TickFilterPipeline {
+	return NewTickFilterPipeline(
+		&DuplicateTickFilter{},
+		&ZeroPriceFilter{},
+		&StubQuoteFilter{0.01, 0.1},
+		&CrossedQuoteFilter{},
+		&PriceJumpFilter{50, 5, 0.01, 3},
+		&SessionFilter{TimeOfDay{9, 30, 0}, TimeOfDay{16, 0, 0}, nil, true},
+	)
+}
+
+func (p *TickFilterPipeline) Apply(symbol string, ticks TickArray) (TickArray, *FilterReport) {
+	report := FilterReport{Symbol: symbol, Input: len(ticks)}
+
+	kept := ticks
+	for _, f := range p.Filters {
+		var removed TickArray
+		kept, removed = f.Apply(kept)
+		report.Results = append(report.Results, FilterResult{f.Name(), removed})
+	}
+	report.Output = len(kept)
+
+	if p.OnReport != nil {
+		p.OnReport(&report)
+	}
+	return kept, &report
+}
+
+func splitTicks(ticks TickArray, remove func(t *Tick) bool) (TickArray, TickArray) {
+	var kept, removed TickArray
+	for _, t := range ticks {
+		if remove(t) {
+			removed = append(removed, t)
+		} else {
+			kept = append(kept, t)
+		}
+	}
+	return kept, removed
+}
+
+// Removes trades with zero or negative price and quotes with zero or negative side
+type ZeroPriceFilter struct{}
+
+func (*ZeroPriceFilter) Name() string {
+	return "zero_price"
+}
+
+func (*ZeroPriceFilter) Apply(ticks TickArray) (TickArray, TickArray) {
+	return splitTicks(ticks, func(t *Tick) bool {
+		if t.LastSize > 0 && t.LastPrice <= 0 {
+			return true
+		}
+		if t.isQuoteEvent() && (t.BidPrice <= 0 || t.AskPrice <= 0) {
+			return true
+		}
+		return false
+	})
+}
+
+// Removes market maker stub quotes: bid at MaxBid or below or spread wider than MaxSpread part of bid
+type StubQuoteFilter struct {
+	MaxBid    float64
+	MaxSpread float64
+}
+
+func (*StubQuoteFilter) Name() string {
+	return "stub_quote"
+}
+
+func (f *StubQuoteFilter) Apply(ticks TickArray) (TickArray, TickArray) {
+	return splitTicks(ticks, func(t *Tick) bool {
+		if !t.isQuoteEvent() || t.BidPrice <= 0 || t.AskPrice <= 0 {
+			return false
+		}
+		if t.BidPrice <= f.MaxBid {
+			return true
+		}
+		return f.MaxSpread > 0 && (t.AskPrice-t.BidPrice)/t.BidPrice > f.MaxSpread
+	})
+}
+
+// Removes quotes with bid above ask. Locked quotes are removed too if RemoveLocked is set
+type CrossedQuoteFilter struct {
+	RemoveLocked bool
+}
+
+func (*CrossedQuoteFilter) Name() string {
+	return "crossed_quote"
+}
+
+func (f *CrossedQuoteFilter) Apply(ticks TickArray) (TickArray, TickArray) {
+	return splitTicks(ticks, func(t *Tick) bool {
+		if !t.isQuoteEvent() || t.BidPrice <= 0 || t.AskPrice <= 0 {
+			return false
+		}
+		return t.BidPrice > t.AskPrice || (f.RemoveLocked && t.BidPrice == t.AskPrice)
+	})
+}
+
+// Removes trades which are further than Sigmas standard deviations From median of last Window trades.
+// Deviation is never less than MinDeviation, so flat prices don't make every move an outlier. Confirm consecutive
+// jumped trades which agree with each other are a new price level: they are kept and the window starts again From
+// them. Jumps which are shorter than Confirm trades, including ones at the end of ticks, are removed. Quotes are kept
+type PriceJumpFilter struct {
+	Window       int
+	Sigmas       float64
+	MinDeviation float64
+	Confirm      int
+}
+
+func (*PriceJumpFilter) Name() string {
+	return "price_jump"
+}
+
+func (f *PriceJumpFilter) Apply(ticks TickArray) (TickArray, TickArray) {
+	removed := make(map[*Tick]bool)
+	var window []float64
+	var jumped []*Tick // Consecutive outliers, they can start a new level
+	var band float64
+
+	push := func(price float64) {
+		if len(window) >= f.Window {
+			window = window[1:]
+		}
+		window = append(window, price)
+	}
+	removeJumped := func() {
+		for _, t := range jumped {
+			removed[t] = true
+		}
+		jumped = nil
+	}
+
+	for _, t := range ticks {
+		if !t.HasTrade() {
+			continue
+		}
+		if f.Window <= 0 || len(window) < f.Window {
+			push(t.LastPrice)
+			continue
+		}
+
+		median, sigma := medianAndSigma(window)
+		if sigma < f.MinDeviation {
+			sigma = f.MinDeviation
+		}
+		if math.Abs(t.LastPrice-median) <= f.Sigmas*sigma {
+			removeJumped()
+			push(t.LastPrice)
+			continue
+		}
+
+		if len(jumped) > 0 && math.Abs(t.LastPrice-jumped[0].LastPrice) > band {
+			removeJumped()
+		}
+		if len(jumped) == 0 {
+			band = f.Sigmas * sigma
+		}
+		jumped = append(jumped, t)
+
+		if f.Confirm > 0 && len(jumped) >= f.Confirm {
+			window = window[:0]
+			for _, j := range jumped {
+				window = append(window, j.LastPrice)
+			}
+			jumped = nil
+		}
+	}
+	removeJumped()
+
+	return splitTicks(ticks, func(t *Tick) bool {
+		return removed[t]
+	})
+}
+
+func medianAndSigma(values []float64) (float64, float64) {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(values))
+
+	return median, math.Sqrt(variance)
+}
+
+// Removes ticks outside of session [Start, End]. Time of day is taken in Location or in tick time if it's nil.
+// Opening and closing prints are always kept, they can be reported after the session. If TradesOnly is set quotes
+// are kept
+type SessionFilter struct {
+	Start      TimeOfDay
+	End        TimeOfDay
+	Location   *time.Location
+	TradesOnly bool
+}
+
+func (*SessionFilter) Name() string {
+	return "out_of_session"
+}
+
+func (f *SessionFilter) Apply(ticks TickArray) (TickArray, TickArray) {
+	start := secondsOfDay(f.Start.Hour, f.Start.Minute, f.Start.Second)
+	end := secondsOfDay(f.End.Hour, f.End.Minute, f.End.Second)
+
+	return splitTicks(ticks, func(t *Tick) bool {
+		if f.TradesOnly && !t.HasTrade() {
+			return false
+		}
+		if t.IsOpening || t.IsClosing {
+			return false
+		}
+		dt := t.Datetime
+		if f.Location != nil {
+			dt = dt.In(f.Location)
+		}
+		s := secondsOfDay(dt.Hour(), dt.Minute(), dt.Second())
+		return s < start || s > end
+	})
+}
+
+func secondsOfDay(hour int, minute int, second int) int {
+	return hour*3600 + minute*60 + second
+}
+
+// Removes repeated ticks with the same time and the same values. Arrival sequence is ignored
+type DuplicateTickFilter struct{}
+
+func (*DuplicateTickFilter) Name() string {
+	return "duplicate"
+}
+
+func (*DuplicateTickFilter) Apply(ticks TickArray) (TickArray, TickArray) {
+	seen := make(map[string]struct{})
+	return splitTicks(ticks, func(t *Tick) bool {
+		key := t.String()
+		if _, ok := seen[key]; ok {
+			return true
+		}
+		seen[key] = struct{}{}
+		return false
+	})
+}
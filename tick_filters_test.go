@@ -0,0 +1,141 @@
+package marketdata
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tradeAt(minutes int, price float64) *Tick {
+	dt := time.Date(2018, 11, 1, 9, 30, 0, 0, time.UTC).Add(time.Duration(minutes) * time.Minute)
+	return &Tick{Symbol: "SPY", LastPrice: price, LastSize: 100, Datetime: dt, BidPrice: -1, AskPrice: -1,
+		BidSize: -1, AskSize: -1}
+}
+
+func quoteAt(minutes int, bid float64, ask float64) *Tick {
+	dt := time.Date(2018, 11, 1, 9, 30, 0, 0, time.UTC).Add(time.Duration(minutes) * time.Minute)
+	return &Tick{Symbol: "SPY", LastPrice: -1, LastSize: -1, Datetime: dt, BidPrice: bid, AskPrice: ask,
+		BidSize: 1, AskSize: 1, BidExch: "P", AskExch: "Q"}
+}
+
+func TestTickFilters(t *testing.T) {
+	ticks := TickArray{
+		quoteAt(0, 0, 100),
+		quoteAt(1, 0.01, 100),
+		quoteAt(2, 79.25, 100),
+		quoteAt(3, 79.26, 79.25),
+		quoteAt(4, 79.25, 79.26),
+		tradeAt(5, 79.26),
+		tradeAt(5, 79.26),
+	}
+
+	kept, removed := (&ZeroPriceFilter{}).Apply(ticks)
+	assert.Equal(t, 6, len(kept))
+	assert.Equal(t, ticks[0], removed[0])
+
+	kept, removed = (&StubQuoteFilter{0.01, 0.1}).Apply(kept)
+	assert.Equal(t, TickArray{ticks[1], ticks[2]}, removed)
+
+	kept, removed = (&CrossedQuoteFilter{}).Apply(kept)
+	assert.Equal(t, TickArray{ticks[3]}, removed)
+
+	kept, removed = (&DuplicateTickFilter{}).Apply(kept)
+	assert.Equal(t, 1, len(removed))
+	assert.Equal(t, 2, len(kept))
+}
+
+func TestPriceJumpAndSessionFilters(t *testing.T) {
+	var ticks TickArray
+	for i := 0; i < 20; i++ {
+		ticks = append(ticks, tradeAt(i, 100+float64(i%3)*0.01))
+	}
+	ticks = append(ticks, tradeAt(20, 110), tradeAt(21, 100.02))
+
+	kept, removed := (&PriceJumpFilter{10, 5, 0.01, 3}).Apply(ticks)
+	assert.Equal(t, 21, len(kept))
+	assert.Equal(t, 110.0, removed[0].LastPrice)
+
+	// Level shift is confirmed by consecutive trades at the new level
+	var shifted TickArray
+	for i := 0; i < 260; i++ {
+		price := 10.0
+		if i >= 60 {
+			price = 10.5
+		}
+		shifted = append(shifted, tradeAt(i, price))
+	}
+	shifted = append(shifted, tradeAt(260, 20), tradeAt(261, 20), tradeAt(262, 10.5), tradeAt(263, 20))
+	kept, removed = (&PriceJumpFilter{50, 5, 0.01, 3}).Apply(shifted)
+	assert.Equal(t, 261, len(kept))
+	assert.Equal(t, TickArray{shifted[260], shifted[261], shifted[263]}, removed)
+
+	late := tradeAt(400, 100)
+	closing := tradeAt(400, 100)
+	closing.IsClosing = true
+	early := quoteAt(-60, 99, 101)
+
+	f := SessionFilter{TimeOfDay{9, 30, 0}, TimeOfDay{16, 0, 0}, nil, false}
+	kept, removed = f.Apply(TickArray{early, ticks[0], late, closing})
+	assert.Equal(t, TickArray{ticks[0], closing}, kept)
+	assert.Equal(t, TickArray{early, late}, removed)
+
+	f.TradesOnly = true
+	kept, _ = f.Apply(TickArray{early, late})
+	assert.Equal(t, TickArray{early}, kept)
+}
+
+func TestTickFilterPipeline_PSCC(t *testing.T) {
+	dRange := DateRange{timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 5)}
+	ticks, err := mockActiveTick().GetTicks("PSCC", dRange, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reported *FilterReport
+	pipeline := DefaultTickFilterPipeline()
+	pipeline.OnReport = func(r *FilterReport) {
+		reported = r
+	}
+
+	kept, report := pipeline.Apply("PSCC", ticks)
+	assert.Equal(t, report, reported)
+	assert.Equal(t, len(ticks), report.Input)
+	assert.Equal(t, len(kept), report.Output)
+	assert.True(t, report.RemovedCount() > 0)
+	assert.Equal(t, len(pipeline.Filters), len(report.Results))
+
+	for _, tk := range kept {
+		if tk.isQuoteEvent() {
+			assert.True(t, tk.BidPrice > 0.01 && tk.AskPrice >= tk.BidPrice, tk.String())
+		}
+	}
+}
+
+func TestJsonStorage_ReadTickFilters(t *testing.T) {
+	testDir := "./test_data/tick_filters"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	day := timeOnTheFly(2018, 11, 1)
+	ticks := TickArray{quoteAt(0, 0, 100), quoteAt(1, 79.25, 79.26)}
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC}
+	_, err := storage.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.saveTicksToFile(&ticks, path.Join(testDir, "ticks/quotes_trades/SPY", day.Format(tickfilelayout)+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storage.ReadTickFilters = NewTickFilterPipeline(&ZeroPriceFilter{})
+	loaded, err := storage.GetStoredTicks("SPY", DateRange{day, day}, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(loaded))
+	assert.Equal(t, 79.25, loaded[0].BidPrice)
+}
@@ -0,0 +1,320 @@
+package marketdata
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Keeps ticks of requested kind. Tick with both trade and quote is kept if any of them is requested
+func (t TickArray) filterKind(quotes bool, trades bool) TickArray {
+	if quotes && trades {
+		return t
+	}
+	var filtered TickArray
+	for i := range t {
+		if (trades && t[i].HasTrade()) || (quotes && t[i].isQuoteEvent()) {
+			filtered = append(filtered, t[i])
+		}
+	}
+	return filtered
+}
+
+// Folders which can serve request. Dedicated folder goes first, quotes_trades folder serves quotes-only and
+// trades-only requests as well
+func (p *JsonStorage) tickViewFolders(quotes bool, trades bool) []string {
+	folder := p.generateTicksFolderName(quotes, trades)
+	combined := p.generateTicksFolderName(true, true)
+	if folder == combined {
+		return []string{combined}
+	}
+	return []string{folder, combined}
+}
+
+// Ticks of single kind. Days which are stored only in quotes_trades folder are filtered on read
+func (p *JsonStorage) getStoredTickView(symbol string, dRange DateRange, quotes bool, trades bool) (TickArray, error) {
+	ticks, err := p.getStoredTickDays(symbol, dRange, p.tickViewFolders(quotes, trades)...)
+	if err != nil {
+		return nil, err
+	}
+	return ticks.filterKind(quotes, trades), nil
+}
+
+// Removes dates which are already stored in quotes_trades folder, so quotes-only and trades-only updates don't
+// download them again
+func (p *JsonStorage) withoutCombinedDates(symbol string, quotes bool, trades bool, dates []time.Time) []time.Time {
+	folders := p.tickViewFolders(quotes, trades)
+	if len(folders) == 1 {
+		return dates
+	}
+
+	combinedMeta := loadMetaIfExists(p.symbolTicksMetaPath(symbol, true, true))
+	stored := combinedMeta.datesSet()
+
+	var missing []time.Time
+	for _, d := range dates {
+		if _, ok := stored[d.UTC().Unix()]; !ok {
+			missing = append(missing, d)
+		}
+	}
+	return missing
+}
+
+// Trading days of range which aren't listed in meta of any folder serving request
+func (p *JsonStorage) unlistedTickDates(symbol string, dRange DateRange, quotes bool, trades bool) []time.Time {
+	listed := make(map[int64]struct{})
+	for _, f := range p.tickViewFolders(quotes, trades) {
+		meta := loadMetaIfExists(path.Join(p.Path, "ticks", f, metaFolder, symbolStoragePath(symbol)+".json"))
+		for d := range meta.datesSet() {
+			listed[d] = struct{}{}
+		}
+	}
+
+	var missing []time.Time
+	from := dRange.From.UTC()
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	for d := start; !d.After(dRange.To); d = d.AddDate(0, 0, 1) {
+		if !p.marketMode().isTradingDay(d) {
+			continue
+		}
+		if _, ok := listed[d.Unix()]; !ok {
+			missing = append(missing, d)
+		}
+	}
+	return missing
+}
+
+type TickDedupReport struct {
+	Removed []string // Quotes or trades files which are covered by quotes_trades file
+	Merged  []string // quotes_trades files created From quotes and trades files of the same day
+	Skipped []string // Files which have more ticks than quotes_trades file of the same day
+}
+
+// Moves quotes and trades days into quotes_trades folder. Separate file is removed if quotes_trades file of the
+// same day exists and has at least the same number of ticks of this kind. Days which exist both in quotes and
+// trades folders are merged into quotes_trades file. If symbols aren't set all symbols are deduplicated.
+func (p *JsonStorage) DeduplicateTicks(symbols ...string) (*TickDedupReport, error) {
+	err := p.ensureManifest(true)
+	if err != nil {
+		return nil, err
+	}
+	if len(symbols) == 0 {
+		symbols, err = p.separateTickSymbols()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := TickDedupReport{}
+	for _, s := range symbols {
+		err := p.deduplicateSymbolTicks(s, &report)
+		if err != nil {
+			return nil, errors.Wrapf(err, "DeduplicateTicks() Symbol: %v", s)
+		}
+	}
+	return &report, nil
+}
+
+// Symbols which have quotes or trades folders
+func (p *JsonStorage) separateTickSymbols() ([]string, error) {
+	set := make(map[string]struct{})
+	for _, folder := range []string{p.generateTicksFolderName(true, false), p.generateTicksFolderName(false, true)} {
+		dir := path.Join(p.Path, "ticks", folder)
+		if !fileExists(dir) {
+			continue
+		}
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			if f.IsDir() && !strings.HasPrefix(f.Name(), ".") {
+				set[f.Name()] = struct{}{}
+			}
+		}
+	}
+
+	var symbols []string
+	for s := range set {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+	return symbols, nil
+}
+
+func (p *JsonStorage) deduplicateSymbolTicks(symbol string, report *TickDedupReport) error {
+	updateLock, err := p.lockSymbolUpdate(symbol)
+	if err != nil {
+		return err
+	}
+	defer updateLock.Unlock()
+
+	quotesFolder := p.symbolTicksFolder(symbol, true, false)
+	tradesFolder := p.symbolTicksFolder(symbol, false, true)
+	combinedFolder := p.symbolTicksFolder(symbol, true, true)
+
+	days := make(map[string]struct{})
+	for _, folder := range []string{quotesFolder, tradesFolder} {
+		if !fileExists(folder) {
+			continue
+		}
+		dates, err := p.getStoredTickDates(folder)
+		if err != nil {
+			return err
+		}
+		for _, d := range dates {
+			days[d.Format(tickfilelayout)] = struct{}{}
+		}
+	}
+	if len(days) == 0 {
+		return nil
+	}
+
+	var entries []journalEntry
+	for _, kind := range [][2]bool{{true, false}, {false, true}, {true, true}} {
+		entries = append(entries, journalEntry{Dataset: ticksDataset, Symbol: symbol,
+			Data: p.symbolTicksFolder(symbol, kind[0], kind[1]),
+			Meta: p.symbolTicksMetaPath(symbol, kind[0], kind[1])})
+	}
+	var journalIds []string
+	for _, e := range entries {
+		id, err := p.journal().begin(e)
+		if err != nil {
+			return err
+		}
+		journalIds = append(journalIds, id)
+	}
+
+	dataLock, err := p.lockSymbolData(symbol, true)
+	if err != nil {
+		return err
+	}
+	defer dataLock.Unlock()
+
+	var sorted []string
+	for d := range days {
+		sorted = append(sorted, d)
+	}
+	sort.Strings(sorted)
+
+	for _, d := range sorted {
+		err = p.deduplicateTickDay(d, quotesFolder, tradesFolder, combinedFolder, report)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Meta is rebuilt From files. Folders which became empty are removed
+	for i, e := range entries {
+		if e.Data != combinedFolder {
+			os.Remove(e.Data)
+		}
+		if fileExists(e.Data) || fileExists(e.Meta) {
+			err = p.rebuildMeta(e)
+			if err != nil {
+				return err
+			}
+		}
+		err = p.journal().commit(journalIds[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *JsonStorage) deduplicateTickDay(day string, quotesFolder string, tradesFolder string,
+	combinedFolder string, report *TickDedupReport) error {
+
+	quotesPath := path.Join(quotesFolder, day+".json")
+	tradesPath := path.Join(tradesFolder, day+".json")
+	combinedPath := path.Join(combinedFolder, day+".json")
+
+	if !fileExists(combinedPath) {
+		if !fileExists(quotesPath) || !fileExists(tradesPath) {
+			return nil
+		}
+		quotes, err := p.readTicksFromFile(quotesPath)
+		if err != nil {
+			return err
+		}
+		trades, err := p.readTicksFromFile(tradesPath)
+		if err != nil {
+			return err
+		}
+		merged := mergeTickDays(*quotes, *trades)
+		err = p.saveTicksToFile(&merged, combinedPath)
+		if err != nil {
+			return err
+		}
+		report.Merged = append(report.Merged, combinedPath)
+		return p.removeTickFiles(report, quotesPath, tradesPath)
+	}
+
+	combined, err := p.readTicksFromFile(combinedPath)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range []struct {
+		pth    string
+		quotes bool
+	}{{quotesPath, true}, {tradesPath, false}} {
+		if !fileExists(v.pth) {
+			continue
+		}
+		separate, err := p.readTicksFromFile(v.pth)
+		if err != nil {
+			return err
+		}
+		if len(combined.filterKind(v.quotes, !v.quotes)) < len(separate.filterKind(v.quotes, !v.quotes)) {
+			report.Skipped = append(report.Skipped, v.pth)
+			continue
+		}
+		err = p.removeTickFiles(report, v.pth)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *JsonStorage) removeTickFiles(report *TickDedupReport, paths ...string) error {
+	for _, pth := range paths {
+		err := os.Remove(pth)
+		if err != nil {
+			return err
+		}
+		report.Removed = append(report.Removed, pth)
+	}
+	return nil
+}
+
+// Merges quotes and trades of the same day by time. Order of ticks with the same time is kept and Seq is
+// renumbered, as it would be in quotes_trades response
+func mergeTickDays(quotes TickArray, trades TickArray) TickArray {
+	merged := make(TickArray, 0, len(quotes)+len(trades))
+	merged = append(merged, quotes...)
+	merged = append(merged, trades...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Datetime.Before(merged[j].Datetime)
+	})
+	for i := range merged {
+		merged[i].Seq = int64(i)
+	}
+	return merged
+}
+
+func (p *JsonStorage) symbolTicksFolder(symbol string, quotes bool, trades bool) string {
+	return path.Join(p.Path, "ticks", p.generateTicksFolderName(quotes, trades), symbolStoragePath(symbol))
+}
+
+func (p *JsonStorage) symbolTicksMetaPath(symbol string, quotes bool, trades bool) string {
+	return path.Join(p.Path, "ticks", p.generateTicksFolderName(quotes, trades), metaFolder,
+		symbolStoragePath(symbol)+".json")
+}
@@ -0,0 +1,127 @@
+package marketdata
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns quote at 10:00 and trade at 10:01 for every requested day
+type quotesTradesMock struct {
+	dailyTicksMock
+}
+
+func (p *quotesTradesMock) GetTicks(symbol string, dRange DateRange, quotes bool, trades bool) (TickArray, error) {
+	p.mu.Lock()
+	p.requested = append(p.requested, dRange.From)
+	p.mu.Unlock()
+
+	d := dRange.From
+	var ticks TickArray
+	if quotes {
+		ticks = append(ticks, &Tick{Symbol: symbol, LastPrice: -1, LastSize: -1, BidPrice: 10, AskPrice: 10.01,
+			BidSize: 1, AskSize: 1, Datetime: time.Date(d.Year(), d.Month(), d.Day(), 10, 0, 0, 0, time.UTC)})
+	}
+	if trades {
+		ticks = append(ticks, &Tick{Symbol: symbol, LastPrice: 10, LastSize: 100, BidPrice: -1, AskPrice: -1,
+			BidSize: -1, AskSize: -1, Datetime: time.Date(d.Year(), d.Month(), d.Day(), 10, 1, 0, 0, time.UTC)})
+	}
+	return ticks, nil
+}
+
+func TestJsonStorage_TickViews(t *testing.T) {
+	testDir := "./test_data/tick_views"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	provider := &quotesTradesMock{}
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC, Market: WeekdaysMarket, Provider: provider}
+
+	params := TickUpdateParams{Symbol: "SPY", FromDate: timeOnTheFly(2018, 11, 1), ToDate: timeOnTheFly(2018, 11, 2),
+		Quotes: true, Trades: true}
+	err := storage.UpdateSymbolTicks(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(provider.requested))
+
+	// Trades-only update is served by quotes_trades days
+	params.Quotes = false
+	err = storage.UpdateSymbolTicks(params)
+	assert.IsType(t, &ErrNothingToDownload{}, errors.Cause(err))
+	assert.Equal(t, 2, len(provider.requested))
+	assert.False(t, fileExists(path.Join(testDir, "ticks/trades/SPY")))
+
+	dRange := DateRange{timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 2)}
+	trades, err := storage.GetStoredTicks("SPY", dRange, false, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(trades))
+	for _, tick := range trades {
+		assert.True(t, tick.HasTrade())
+	}
+
+	quotes, err := storage.GetStoredTicks("SPY", dRange, true, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(quotes))
+	for _, tick := range quotes {
+		assert.True(t, tick.HasQuote())
+	}
+
+	// Only missing day is downloaded
+	params.ToDate = timeOnTheFly(2018, 11, 5)
+	err = storage.UpdateSymbolTicks(params)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(provider.requested))
+	assert.True(t, fileExists(path.Join(testDir, "ticks/trades/SPY/2018-11-05.json")))
+}
+
+func TestJsonStorage_DeduplicateTicks(t *testing.T) {
+	testDir := "./test_data/tick_dedup"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	provider := &quotesTradesMock{}
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC, Market: WeekdaysMarket, Provider: provider}
+	if _, err := storage.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	save := func(quotes bool, trades bool, day time.Time) {
+		ticks, _ := provider.GetTicks("SPY", DateRange{day, day}, quotes, trades)
+		pth := path.Join(storage.symbolTicksFolder("SPY", quotes, trades), day.Format(tickfilelayout)+".json")
+		if err := storage.saveTicksToFile(&ticks, pth); err != nil {
+			t.Fatal(err)
+		}
+	}
+	save(true, true, timeOnTheFly(2018, 11, 1))
+	save(false, true, timeOnTheFly(2018, 11, 1))
+	save(true, false, timeOnTheFly(2018, 11, 2))
+	save(false, true, timeOnTheFly(2018, 11, 2))
+	save(true, false, timeOnTheFly(2018, 11, 5))
+
+	report, err := storage.DeduplicateTicks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{path.Join(storage.symbolTicksFolder("SPY", true, true), "2018-11-02.json")}, report.Merged)
+	assert.Equal(t, 3, len(report.Removed))
+	assert.Equal(t, 0, len(report.Skipped))
+
+	assert.False(t, fileExists(storage.symbolTicksFolder("SPY", false, true)))
+	assert.True(t, fileExists(path.Join(storage.symbolTicksFolder("SPY", true, false), "2018-11-05.json")))
+
+	merged, err := storage.readTicksFromFile(path.Join(storage.symbolTicksFolder("SPY", true, true), "2018-11-02.json"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(*merged))
+	assert.True(t, (*merged)[0].Datetime.Before((*merged)[1].Datetime))
+
+	meta := loadMetaIfExists(storage.symbolTicksMetaPath("SPY", true, true))
+	assert.Equal(t, 2, len(meta.ListedDates))
+	meta = loadMetaIfExists(storage.symbolTicksMetaPath("SPY", true, false))
+	assert.Equal(t, 1, len(meta.ListedDates))
+	assert.False(t, fileExists(path.Join(testDir, journalFileName)))
+}
@@ -0,0 +1,265 @@
+package marketdata
+
+import (
+	"fmt"
+	"time"
+)
+
+type EventType string
+
+const (
+	TradeEvent EventType = "trade"
+	QuoteEvent EventType = "quote"
+)
+
+type Trade struct {
+	Symbol    string
+	Datetime  time.Time
+	Seq       int64
+	Price     float64
+	Size      int64
+	Exch      string
+	Cond1     string
+	Cond2     string
+	Cond3     string
+	Cond4     string
+	IsOpening bool
+	IsClosing bool
+	Source    string
+	Exact     *Decimal `json:",omitempty"`
+}
+
+func (t *Trade) String() string {
+	return fmt.Sprintf("%v,%v,%v,%v,%v", t.Datetime.UnixNano(), t.Symbol, t.Price, t.Size, t.Exch)
+}
+
+type Quote struct {
+	Symbol   string
+	Datetime time.Time
+	Seq      int64
+	BidPrice float64
+	AskPrice float64
+	BidSize  int64
+	AskSize  int64
+	BidExch  string
+	AskExch  string
+	Cond     string
+	Source   string
+	ExactBid *Decimal `json:",omitempty"`
+	ExactAsk *Decimal `json:",omitempty"`
+}
+
+func (q *Quote) String() string {
+	return fmt.Sprintf("%v,%v,%v,%v,%v,%v", q.Datetime.UnixNano(), q.Symbol, q.BidPrice, q.BidSize, q.AskPrice,
+		q.AskSize)
+}
+
+// Tagged market event. Exactly one of Trade and Quote is set according To Type
+type MarketEvent struct {
+	Type  EventType
+	Trade *Trade `json:",omitempty"`
+	Quote *Quote `json:",omitempty"`
+}
+
+func (e *MarketEvent) Datetime() time.Time {
+	if e.Type == TradeEvent {
+		return e.Trade.Datetime
+	}
+	return e.Quote.Datetime
+}
+
+func (e *MarketEvent) Symbol() string {
+	if e.Type == TradeEvent {
+		return e.Trade.Symbol
+	}
+	return e.Quote.Symbol
+}
+
+// Legacy tick of event
+func (e *MarketEvent) Tick() *Tick {
+	if e.Type == TradeEvent {
+		return e.Trade.Tick()
+	}
+	return e.Quote.Tick()
+}
+
+// Tick carries quote if quote fields aren't -1 sentinels. Unlike HasQuote, empty quotes (0 bid and ask) are
+// kept, they are still quote events in provider stream
+func (t *Tick) isQuoteEvent() bool {
+	if t.BidPrice < 0 || t.AskPrice < 0 {
+		return false
+	}
+	return t.BidPrice > 0 || t.AskPrice > 0 || t.BidSize > 0 || t.AskSize > 0 ||
+		t.BidExch != "" || t.AskExch != "" || t.CondQuote != ""
+}
+
+func (t *Tick) Trade() *Trade {
+	trade := Trade{
+		Symbol:    t.Symbol,
+		Datetime:  t.Datetime,
+		Seq:       t.Seq,
+		Price:     t.LastPrice,
+		Size:      t.LastSize,
+		Exch:      t.LastExch,
+		Cond1:     t.Cond1,
+		Cond2:     t.Cond2,
+		Cond3:     t.Cond3,
+		Cond4:     t.Cond4,
+		IsOpening: t.IsOpening,
+		IsClosing: t.IsClosing,
+		Source:    t.Source,
+	}
+	if t.Exact != nil {
+		last := t.Exact.LastPrice
+		trade.Exact = &last
+	}
+	return &trade
+}
+
+func (t *Tick) Quote() *Quote {
+	quote := Quote{
+		Symbol:   t.Symbol,
+		Datetime: t.Datetime,
+		Seq:      t.Seq,
+		BidPrice: t.BidPrice,
+		AskPrice: t.AskPrice,
+		BidSize:  t.BidSize,
+		AskSize:  t.AskSize,
+		BidExch:  t.BidExch,
+		AskExch:  t.AskExch,
+		Cond:     t.CondQuote,
+		Source:   t.Source,
+	}
+	if t.Exact != nil {
+		bid, ask := t.Exact.BidPrice, t.Exact.AskPrice
+		quote.ExactBid = &bid
+		quote.ExactAsk = &ask
+	}
+	return &quote
+}
+
+// Events of tick. Legacy tick can have both trade and quote, trade goes first
+func (t *Tick) Events() []MarketEvent {
+	var events []MarketEvent
+	if t.HasTrade() {
+		events = append(events, MarketEvent{Type: TradeEvent, Trade: t.Trade()})
+	}
+	if t.isQuoteEvent() {
+		events = append(events, MarketEvent{Type: QuoteEvent, Quote: t.Quote()})
+	}
+	return events
+}
+
+// Legacy tick with -1 in quote fields
+func (tr *Trade) Tick() *Tick {
+	tick := Tick{
+		Symbol:    tr.Symbol,
+		IsOpening: tr.IsOpening,
+		IsClosing: tr.IsClosing,
+		LastPrice: tr.Price,
+		LastSize:  tr.Size,
+		LastExch:  tr.Exch,
+		Datetime:  tr.Datetime,
+		Seq:       tr.Seq,
+		BidPrice:  -1,
+		AskPrice:  -1,
+		BidSize:   -1,
+		AskSize:   -1,
+		Cond1:     tr.Cond1,
+		Cond2:     tr.Cond2,
+		Cond3:     tr.Cond3,
+		Cond4:     tr.Cond4,
+		Source:    tr.Source,
+	}
+	if tr.Exact != nil {
+		missing := DecimalFromFloat(-1, tr.Exact.Scale)
+		tick.Exact = &TickDecimals{*tr.Exact, missing, missing}
+	}
+	return &tick
+}
+
+// Legacy tick with -1 in trade fields
+func (q *Quote) Tick() *Tick {
+	tick := Tick{
+		Symbol:    q.Symbol,
+		LastPrice: -1,
+		LastSize:  -1,
+		Datetime:  q.Datetime,
+		Seq:       q.Seq,
+		BidExch:   q.BidExch,
+		AskExch:   q.AskExch,
+		BidPrice:  q.BidPrice,
+		AskPrice:  q.AskPrice,
+		BidSize:   q.BidSize,
+		AskSize:   q.AskSize,
+		CondQuote: q.Cond,
+		Source:    q.Source,
+	}
+	if q.ExactBid != nil && q.ExactAsk != nil {
+		tick.Exact = &TickDecimals{DecimalFromFloat(-1, q.ExactBid.Scale), *q.ExactBid, *q.ExactAsk}
+	}
+	return &tick
+}
+
+// Discriminated stream of ticks in the same order
+func (t TickArray) Events() []MarketEvent {
+	var events []MarketEvent
+	for _, tick := range t {
+		events = append(events, tick.Events()...)
+	}
+	return events
+}
+
+func (t TickArray) Trades() []*Trade {
+	var trades []*Trade
+	for _, tick := range t {
+		if tick.HasTrade() {
+			trades = append(trades, tick.Trade())
+		}
+	}
+	return trades
+}
+
+func (t TickArray) Quotes() []*Quote {
+	var quotes []*Quote
+	for _, tick := range t {
+		if tick.isQuoteEvent() {
+			quotes = append(quotes, tick.Quote())
+		}
+	}
+	return quotes
+}
+
+func EventsToTicks(events []MarketEvent) TickArray {
+	var ticks TickArray
+	for i := range events {
+		ticks = append(ticks, events[i].Tick())
+	}
+	return ticks
+}
+
+// Stored trades. Dedicated trades files are used if they exist, otherwise trades are taken From quotes_trades files
+func (p *JsonStorage) GetStoredTrades(symbol string, dRange DateRange) ([]*Trade, error) {
+	err := p.ensureManifest(false)
+	if err != nil {
+		return nil, err
+	}
+	ticks, err := p.getStoredTickDays(symbol, dRange, p.tickViewFolders(false, true)...)
+	if err != nil {
+		return nil, err
+	}
+	return ticks.Trades(), nil
+}
+
+// Stored quotes. Dedicated quotes files are used if they exist, otherwise quotes are taken From quotes_trades files
+func (p *JsonStorage) GetStoredQuotes(symbol string, dRange DateRange) ([]*Quote, error) {
+	err := p.ensureManifest(false)
+	if err != nil {
+		return nil, err
+	}
+	ticks, err := p.getStoredTickDays(symbol, dRange, p.tickViewFolders(true, false)...)
+	if err != nil {
+		return nil, err
+	}
+	return ticks.Quotes(), nil
+}
@@ -0,0 +1,105 @@
+package marketdata
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tradeQuoteTicksMock() TickArray {
+	dt := time.Date(2018, 11, 5, 14, 30, 0, 0, time.UTC)
+	return TickArray{
+		{Symbol: "SPY", LastPrice: 270.1, LastSize: 100, LastExch: "Q", Datetime: dt, Seq: 0, BidPrice: -1,
+			AskPrice: -1, BidSize: -1, AskSize: -1, Cond1: "0"},
+		{Symbol: "SPY", LastPrice: -1, LastSize: -1, Datetime: dt, Seq: 1, BidPrice: 270.09, AskPrice: 270.11,
+			BidSize: 3, AskSize: 5, BidExch: "P", AskExch: "Q"},
+		// Empty quote
+		{Symbol: "SPY", LastPrice: -1, LastSize: -1, Datetime: dt, Seq: 2, BidExch: "0", AskExch: "0"},
+	}
+}
+
+func TestTickArray_Events(t *testing.T) {
+	ticks := tradeQuoteTicksMock()
+
+	events := ticks.Events()
+	assert.Equal(t, 3, len(events))
+	assert.Equal(t, TradeEvent, events[0].Type)
+	assert.Nil(t, events[0].Quote)
+	assert.Equal(t, 270.1, events[0].Trade.Price)
+	assert.Equal(t, QuoteEvent, events[1].Type)
+	assert.Equal(t, int64(5), events[1].Quote.AskSize)
+	assert.Equal(t, QuoteEvent, events[2].Type)
+
+	assert.Equal(t, 1, len(ticks.Trades()))
+	assert.Equal(t, 2, len(ticks.Quotes()))
+
+	// Round trip To legacy ticks
+	assert.Equal(t, ticks, EventsToTicks(events))
+
+	// Legacy tick with both trade and quote gives two events
+	both := Tick{Symbol: "SPY", LastPrice: 10, LastSize: 1, BidPrice: 9, AskPrice: 11, BidSize: 1, AskSize: 1}
+	events = both.Events()
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, TradeEvent, events[0].Type)
+	assert.Equal(t, QuoteEvent, events[1].Type)
+}
+
+func TestTrade_TickExact(t *testing.T) {
+	price, _ := ParseDecimal("270.10", 2)
+	trade := Trade{Symbol: "SPY", Price: 270.1, Size: 100, Exact: &price}
+
+	tick := trade.Tick()
+	assert.Equal(t, price, tick.Exact.LastPrice)
+	assert.Equal(t, int64(-100), tick.Exact.BidPrice.Units)
+	assert.Equal(t, &price, tick.Trade().Exact)
+}
+
+func TestJsonStorage_GetStoredTradesAndQuotes(t *testing.T) {
+	testDir := "./test_data/trade_quote"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC}
+	_, err := storage.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := timeOnTheFly(2018, 11, 5)
+
+	combined := tradeQuoteTicksMock()
+	err = storage.saveTicksToFile(&combined, path.Join(testDir, "ticks/quotes_trades/SPY", day.Format(tickfilelayout)+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := DateRange{day, day.Add(time.Hour * 23)}
+	trades, err := storage.GetStoredTrades("SPY", rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(trades))
+
+	quotes, err := storage.GetStoredQuotes("SPY", rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(quotes))
+
+	// Dedicated trades file is preferred
+	onlyTrades := TickArray{combined[0], combined[0]}
+	err = storage.saveTicksToFile(&onlyTrades, path.Join(testDir, "ticks/trades/SPY", day.Format(tickfilelayout)+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trades, err = storage.GetStoredTrades("SPY", rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(trades))
+
+	_, err = storage.GetStoredQuotes("QQQ", rng)
+	assert.Error(t, err)
+}
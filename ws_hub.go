@@ -0,0 +1,406 @@
+package marketdata
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	TradesChannel = "trades"
+	QuotesChannel = "quotes"
+	BarsChannel   = "bars" // 1-minute bars built From trades
+
+	DefaultHubClientBuffer = 256
+)
+
+// Request of hub client
+type HubRequest struct {
+	Action   string // subscribe, unsubscribe or replay
+	Id       string // Returned in replies and replay messages
+	Symbols  []string
+	Channels []string  // All channels if empty
+	Range    DateRange // Replay range
+}
+
+// Message To hub client
+type HubMessage struct {
+	Type   string  // trade, quote, bar, subscribed, unsubscribed, replay_end or error
+	Id     string  `json:",omitempty"`
+	Symbol string  `json:",omitempty"`
+	Tick   *Tick   `json:",omitempty"`
+	Bar    *Candle `json:",omitempty"`
+	Replay bool    `json:",omitempty"` // Message is part of historical replay
+	Error  string  `json:",omitempty"`
+}
+
+// Connection of hub client. *websocket.Conn of gorilla/websocket satisfies it
+type HubConn interface {
+	ReadJSON(v interface{}) error
+	WriteJSON(v interface{}) error
+	Close() error
+}
+
+// Fans out live ticks and 1-minute bars To many clients subscribed by symbol and channel. Every client has its own
+// buffer, client which doesn't read messages in time is disconnected, so publisher is never blocked. Clients can
+// also request historical replay From storage, it's sent with the same messages.
+type TickHub struct {
+	Storage      Storage // Optional. Serves replay requests
+	ClientBuffer int     // Live messages buffered for every client. 0 means DefaultHubClientBuffer
+
+	mu      sync.Mutex
+	clients map[*hubClient]struct{}
+	bars    map[string]*barBuilder
+}
+
+func NewTickHub(storage Storage) *TickHub {
+	return &TickHub{
+		Storage: storage,
+		clients: make(map[*hubClient]struct{}),
+		bars:    make(map[string]*barBuilder),
+	}
+}
+
+type hubClient struct {
+	conn      HubConn
+	live      chan *HubMessage
+	replies   chan *HubMessage // Replies and replay. Sending To it blocks, so long replay doesn't overflow live buffer
+	done      chan struct{}
+	closeOnce sync.Once
+	subs      map[string]map[string]bool // Symbol -> channels. Guarded by hub mutex
+	slow      bool                       // Client is being disconnected. Guarded by hub mutex
+}
+
+func (c *hubClient) wants(symbol string, channel string) bool {
+	return c.subs[symbol][channel]
+}
+
+// Blocks until message is taken by writer or client is disconnected
+func (c *hubClient) reply(m *HubMessage) bool {
+	select {
+	case c.replies <- m:
+		return true
+	case <-c.done:
+		return false
+	}
+}
+
+func (h *TickHub) Clients() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// Serves client until connection is closed
+func (h *TickHub) Serve(conn HubConn) error {
+	buffer := h.ClientBuffer
+	if buffer <= 0 {
+		buffer = DefaultHubClientBuffer
+	}
+	c := &hubClient{
+		conn:    conn,
+		live:    make(chan *HubMessage, buffer),
+		replies: make(chan *HubMessage),
+		done:    make(chan struct{}),
+		subs:    make(map[string]map[string]bool),
+	}
+
+	h.mu.Lock()
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+	defer h.disconnect(c)
+
+	go h.writeLoop(c)
+
+	for {
+		req := HubRequest{}
+		err := conn.ReadJSON(&req)
+		if err != nil {
+			select {
+			case <-c.done:
+				return nil
+			default:
+				return err
+			}
+		}
+		h.handle(c, &req)
+	}
+}
+
+func (h *TickHub) disconnect(c *hubClient) {
+	c.closeOnce.Do(func() {
+		h.mu.Lock()
+		delete(h.clients, c)
+		h.mu.Unlock()
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// Live messages go first, replay is sent when there is nothing live To send
+func (h *TickHub) writeLoop(c *hubClient) {
+	for {
+		var m *HubMessage
+		select {
+		case <-c.done:
+			return
+		case m = <-c.live:
+		default:
+			select {
+			case <-c.done:
+				return
+			case m = <-c.live:
+			case m = <-c.replies:
+			}
+		}
+
+		if c.conn.WriteJSON(m) != nil {
+			h.disconnect(c)
+			return
+		}
+	}
+}
+
+func (h *TickHub) handle(c *hubClient, req *HubRequest) {
+	channels := req.Channels
+	if len(channels) == 0 {
+		channels = []string{TradesChannel, QuotesChannel, BarsChannel}
+	}
+	for _, ch := range channels {
+		if ch != TradesChannel && ch != QuotesChannel && ch != BarsChannel {
+			c.reply(&HubMessage{Type: "error", Id: req.Id, Error: "Unknown channel " + ch})
+			return
+		}
+	}
+
+	switch req.Action {
+	case "subscribe":
+		h.mu.Lock()
+		for _, s := range req.Symbols {
+			if c.subs[s] == nil {
+				c.subs[s] = make(map[string]bool)
+			}
+			for _, ch := range channels {
+				c.subs[s][ch] = true
+			}
+		}
+		h.mu.Unlock()
+		c.reply(&HubMessage{Type: "subscribed", Id: req.Id})
+
+	case "unsubscribe":
+		h.mu.Lock()
+		for _, s := range req.Symbols {
+			for _, ch := range channels {
+				delete(c.subs[s], ch)
+			}
+			if len(c.subs[s]) == 0 {
+				delete(c.subs, s)
+			}
+		}
+		h.mu.Unlock()
+		c.reply(&HubMessage{Type: "unsubscribed", Id: req.Id})
+
+	case "replay":
+		go h.replay(c, req, channels)
+
+	default:
+		c.reply(&HubMessage{Type: "error", Id: req.Id, Error: "Unknown action " + req.Action})
+	}
+}
+
+// Publishes tick To subscribed clients. Bar of the previous minute is published by CloseBars at the end of the
+// minute or before the first trade of the next minute, whichever comes first
+func (h *TickHub) Publish(tick *Tick) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if tick.HasTrade() {
+		builder, ok := h.bars[tick.Symbol]
+		if !ok {
+			builder = &barBuilder{period: time.Minute}
+			h.bars[tick.Symbol] = builder
+		}
+		if bar := builder.add(tick); bar != nil {
+			h.fanOut(tick.Symbol, BarsChannel, &HubMessage{Type: "bar", Symbol: tick.Symbol, Bar: bar})
+		}
+
+		h.fanOut(tick.Symbol, TradesChannel, &HubMessage{Type: "trade", Symbol: tick.Symbol, Tick: tick})
+	}
+	if tick.isQuoteEvent() {
+		h.fanOut(tick.Symbol, QuotesChannel, &HubMessage{Type: "quote", Symbol: tick.Symbol, Tick: tick})
+	}
+}
+
+// Publishes bars whose minute is over at now. Run calls it at minute boundaries, so the last bar of illiquid symbol
+// isn't held until its next trade
+func (h *TickHub) CloseBars(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for symbol, builder := range h.bars {
+		if bar := builder.closeAt(now); bar != nil {
+			h.fanOut(symbol, BarsChannel, &HubMessage{Type: "bar", Symbol: symbol, Bar: bar})
+		}
+	}
+}
+
+// Should be called with hub mutex held
+func (h *TickHub) fanOut(symbol string, channel string, m *HubMessage) {
+	for c := range h.clients {
+		if c.slow || !c.wants(symbol, channel) {
+			continue
+		}
+		select {
+		case c.live <- m:
+		default:
+			// Slow consumer. Disconnect is done in background as it takes hub mutex
+			c.slow = true
+			go h.disconnect(c)
+		}
+	}
+}
+
+// Publishes ticks of subscription until it's closed or context is cancelled. Bars are closed by timer aligned To
+// minute boundaries
+func (h *TickHub) Run(ctx context.Context, sub *TickSubscription) error {
+	timer := time.NewTimer(untilNextBar(time.Now(), time.Minute))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-timer.C:
+			h.CloseBars(now)
+			timer.Reset(untilNextBar(time.Now(), time.Minute))
+		case tick, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			h.Publish(tick)
+		}
+	}
+}
+
+func (h *TickHub) replay(c *hubClient, req *HubRequest, channels []string) {
+	err := h.replaySymbols(c, req, channels)
+	if err != nil {
+		c.reply(&HubMessage{Type: "error", Id: req.Id, Error: err.Error()})
+		return
+	}
+	c.reply(&HubMessage{Type: "replay_end", Id: req.Id})
+}
+
+func (h *TickHub) replaySymbols(c *hubClient, req *HubRequest, channels []string) error {
+	if h.Storage == nil {
+		return errors.New("Replay isn't supported")
+	}
+
+	wanted := make(map[string]bool)
+	for _, ch := range channels {
+		wanted[ch] = true
+	}
+
+	for _, symbol := range req.Symbols {
+		ok, err := h.replaySymbol(c, req, symbol, wanted)
+		if err != nil || !ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// Replays stored ticks of symbol day by day, so long ranges aren't loaded into memory. Returns false if client
+// is gone
+func (h *TickHub) replaySymbol(c *hubClient, req *HubRequest, symbol string, wanted map[string]bool) (bool, error) {
+	quotes := wanted[QuotesChannel]
+	trades := wanted[TradesChannel] || wanted[BarsChannel]
+	it := NewTickIterator(h.Storage, symbol, req.Range, quotes, trades, IteratorOptions{Prefetch: 1})
+	defer it.Close()
+
+	bars := barBuilder{period: time.Minute}
+	for it.Next() {
+		t := it.Tick()
+		var messages []*HubMessage
+		if t.HasTrade() && wanted[BarsChannel] {
+			if bar := bars.add(t); bar != nil {
+				messages = append(messages, &HubMessage{Type: "bar", Symbol: symbol, Bar: bar})
+			}
+		}
+		if t.HasTrade() && wanted[TradesChannel] {
+			messages = append(messages, &HubMessage{Type: "trade", Symbol: symbol, Tick: t})
+		}
+		if t.isQuoteEvent() && wanted[QuotesChannel] {
+			messages = append(messages, &HubMessage{Type: "quote", Symbol: symbol, Tick: t})
+		}
+
+		for _, m := range messages {
+			m.Id, m.Replay = req.Id, true
+			if !c.reply(m) {
+				return false, nil
+			}
+		}
+	}
+	if it.Err() != nil {
+		return false, errors.Wrapf(it.Err(), "replay Symbol: %v", symbol)
+	}
+
+	// Replay has the whole range, so the last bar is complete
+	if bar := bars.complete(); bar != nil && wanted[BarsChannel] {
+		return c.reply(&HubMessage{Type: "bar", Id: req.Id, Symbol: symbol, Bar: bar, Replay: true}), nil
+	}
+	return true, nil
+}
+
+func untilNextBar(now time.Time, period time.Duration) time.Duration {
+	return now.Truncate(period).Add(period).Sub(now)
+}
+
+// Builds bars of given period From trades according To their tape rules
+type barBuilder struct {
+	period  time.Duration
+	current *Candle
+	closed  time.Time // End of the last bar closed by time. Late trades of closed bars aren't added
+}
+
+// Adds trade. Returns completed bar when trade starts new bar. Prices are set only by prints which are eligible
+// by their tape rule, see applyTrade
+func (b *barBuilder) add(t *Tick) *Candle {
+	start := t.Datetime.Truncate(b.period)
+	if start.Before(b.closed) {
+		return nil
+	}
+
+	var completed *Candle
+	if b.current == nil || !start.Equal(b.current.Datetime) {
+		completed = b.complete()
+		b.current = &Candle{Symbol: t.Symbol, Datetime: start, Source: t.Source}
+	}
+	applyTrade(b.current, t)
+	return completed
+}
+
+// Closes current bar if its period is over at now
+func (b *barBuilder) closeAt(now time.Time) *Candle {
+	if b.current == nil {
+		return nil
+	}
+	end := b.current.Datetime.Add(b.period)
+	if end.After(now) {
+		return nil
+	}
+	b.closed = end
+	return b.complete()
+}
+
+// Takes current bar. Bar without prices, which has only prints not eligible To set them, is dropped
+func (b *barBuilder) complete() *Candle {
+	bar := b.current
+	b.current = nil
+	if bar == nil || !hasTradePrices(bar) {
+		return nil
+	}
+	return bar
+}
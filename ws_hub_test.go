@@ -0,0 +1,206 @@
+package marketdata
+
+import (
+	"io"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// In-memory HubConn. Writes block if client is stalled
+type pipeHubConn struct {
+	requests chan HubRequest
+	messages chan HubMessage
+	closed   chan struct{}
+	once     sync.Once
+	stalled  bool
+}
+
+func newPipeHubConn(stalled bool) *pipeHubConn {
+	return &pipeHubConn{make(chan HubRequest), make(chan HubMessage, 100), make(chan struct{}), sync.Once{},
+		stalled}
+}
+
+func (c *pipeHubConn) ReadJSON(v interface{}) error {
+	select {
+	case r := <-c.requests:
+		*v.(*HubRequest) = r
+		return nil
+	case <-c.closed:
+		return io.EOF
+	}
+}
+
+func (c *pipeHubConn) WriteJSON(v interface{}) error {
+	if c.stalled {
+		<-c.closed
+		return io.ErrClosedPipe
+	}
+	select {
+	case c.messages <- *v.(*HubMessage):
+		return nil
+	case <-c.closed:
+		return io.ErrClosedPipe
+	}
+}
+
+func (c *pipeHubConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *pipeHubConn) next(t *testing.T) HubMessage {
+	select {
+	case m := <-c.messages:
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message")
+		return HubMessage{}
+	}
+}
+
+func TestTickHub_Live(t *testing.T) {
+	hub := NewTickHub(nil)
+	hub.ClientBuffer = 4
+
+	conn := newPipeHubConn(false)
+	go hub.Serve(conn)
+	conn.requests <- HubRequest{Action: "subscribe", Id: "1", Symbols: []string{"SPY"},
+		Channels: []string{TradesChannel, BarsChannel}}
+	assert.Equal(t, HubMessage{Type: "subscribed", Id: "1"}, conn.next(t))
+
+	slow := newPipeHubConn(true)
+	go hub.Serve(slow)
+	slow.requests <- HubRequest{Action: "subscribe", Symbols: []string{"SPY"}}
+	conn.requests <- HubRequest{Action: "subscribe", Channels: []string{"candles"}}
+	assert.Equal(t, "error", conn.next(t).Type)
+	assert.Equal(t, 2, hub.Clients())
+
+	hub.Publish(quoteAt(0, 10, 10.01))
+	hub.Publish(tradeAt(0, 10))
+	hub.Publish(tradeAt(0, 11))
+	hub.Publish(&Tick{Symbol: "QQQ", LastPrice: 1, LastSize: 1})
+	hub.Publish(tradeAt(1, 12))
+
+	assert.Equal(t, 10.0, conn.next(t).Tick.LastPrice)
+	assert.Equal(t, 11.0, conn.next(t).Tick.LastPrice)
+	bar := conn.next(t)
+	assert.Equal(t, "bar", bar.Type)
+	assert.Equal(t, &Candle{Symbol: "SPY", Open: 10, High: 11, Low: 10, Close: 11, AdjClose: 11, Volume: 200,
+		Datetime: time.Date(2018, 11, 1, 9, 30, 0, 0, time.UTC)}, bar.Bar)
+	assert.Equal(t, 12.0, conn.next(t).Tick.LastPrice)
+
+	// Stalled client overflows its buffer and is disconnected, others keep receiving
+	for i := 0; i < 10 && hub.Clients() > 1; i++ {
+		hub.Publish(tradeAt(1, 12))
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 1, hub.Clients())
+	select {
+	case <-slow.closed:
+	default:
+		t.Error("slow client isn't closed")
+	}
+
+	conn.requests <- HubRequest{Action: "unsubscribe", Id: "2", Symbols: []string{"SPY"}}
+	for m := conn.next(t); m.Type != "unsubscribed"; m = conn.next(t) {
+	}
+	hub.Publish(tradeAt(2, 13))
+	conn.Close()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 0, hub.Clients())
+}
+
+func TestTickHub_Replay(t *testing.T) {
+	testDir := "./test_data/tick_hub"
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	storage := JsonStorage{Path: testDir, TimeZone: time.UTC, Market: WeekdaysMarket, Provider: &quotesTradesMock{}}
+	err := storage.UpdateSymbolTicks(TickUpdateParams{Symbol: "SPY", FromDate: timeOnTheFly(2018, 11, 1),
+		ToDate: timeOnTheFly(2018, 11, 2), Quotes: true, Trades: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hub := NewTickHub(&storage)
+	conn := newPipeHubConn(false)
+	go hub.Serve(conn)
+	defer conn.Close()
+
+	conn.requests <- HubRequest{Action: "replay", Id: "r", Symbols: []string{"SPY"},
+		Range: DateRange{timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 2)}}
+
+	var types []string
+	for m := conn.next(t); m.Type != "replay_end"; m = conn.next(t) {
+		assert.True(t, m.Replay)
+		assert.Equal(t, "r", m.Id)
+		types = append(types, m.Type)
+	}
+	assert.Equal(t, []string{"quote", "trade", "quote", "bar", "trade", "bar"}, types)
+
+	conn.requests <- HubRequest{Action: "replay", Id: "q", Symbols: []string{"QQQ"},
+		Range: DateRange{timeOnTheFly(2018, 11, 1), timeOnTheFly(2018, 11, 2)}}
+	m := conn.next(t)
+	assert.Equal(t, "error", m.Type)
+	assert.Equal(t, "q", m.Id)
+}
+
+func TestBarBuilder_TapeRules(t *testing.T) {
+	trade := func(minutes int, price float64, cond string) *Tick {
+		tick := tradeAt(minutes, price)
+		tick.Cond1 = cond
+		return tick
+	}
+	bars := barBuilder{period: time.Minute}
+
+	// Odd lot doesn't open the bar, but its volume is counted
+	assert.Nil(t, bars.add(trade(0, 9, "37")))
+	assert.Nil(t, bars.add(trade(0, 10, "0")))
+	assert.Nil(t, bars.add(trade(0, 50, "2")))
+	bar := bars.add(trade(1, 9, "37"))
+	assert.Equal(t, &Candle{Symbol: "SPY", Open: 10, High: 10, Low: 10, Close: 10, AdjClose: 10, Volume: 300,
+		Datetime: time.Date(2018, 11, 1, 9, 30, 0, 0, time.UTC)}, bar)
+
+	// Bar of odd lots only isn't emitted
+	assert.Nil(t, bars.add(trade(2, 11, "0")))
+	bar = bars.complete()
+	assert.Equal(t, 11.0, bar.Open)
+	assert.Equal(t, time.Date(2018, 11, 1, 9, 32, 0, 0, time.UTC), bar.Datetime)
+	assert.Nil(t, bars.complete())
+}
+
+func TestTickHub_CloseBars(t *testing.T) {
+	hub := NewTickHub(nil)
+	conn := newPipeHubConn(false)
+	go hub.Serve(conn)
+	defer conn.Close()
+	conn.requests <- HubRequest{Action: "subscribe", Id: "1", Symbols: []string{"SPY"},
+		Channels: []string{BarsChannel}}
+	assert.Equal(t, HubMessage{Type: "subscribed", Id: "1"}, conn.next(t))
+
+	minute := time.Date(2018, 11, 1, 9, 30, 0, 0, time.UTC)
+	hub.Publish(tradeAt(0, 10))
+	hub.CloseBars(minute.Add(59 * time.Second))
+	hub.Publish(tradeAt(0, 11))
+
+	// Bar is closed by time without waiting for the next trade
+	hub.CloseBars(minute.Add(time.Minute))
+	bar := conn.next(t)
+	assert.Equal(t, "bar", bar.Type)
+	assert.Equal(t, 11.0, bar.Bar.Close)
+	assert.Equal(t, minute, bar.Bar.Datetime)
+
+	// Late trade of closed bar doesn't open it again
+	hub.Publish(tradeAt(0, 12))
+	hub.Publish(tradeAt(1, 13))
+	hub.CloseBars(minute.Add(2 * time.Minute))
+	bar = conn.next(t)
+	assert.Equal(t, minute.Add(time.Minute), bar.Bar.Datetime)
+	assert.Equal(t, 13.0, bar.Bar.Open)
+
+	assert.Equal(t, 20*time.Second, untilNextBar(minute.Add(100*time.Second), time.Minute))
+}
@@ -0,0 +1,56 @@
+//go:build websocket
+
+package marketdata
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket transport of TickHub. It's built only with websocket tag, so package doesn't depend on gorilla/websocket
+// by default.
+type WebSocketHandler struct {
+	Hub          *TickHub
+	Upgrader     websocket.Upgrader
+	PingInterval time.Duration // Client which doesn't answer ping during two intervals is disconnected. 0 disables pings
+}
+
+func NewWebSocketHandler(hub *TickHub) *WebSocketHandler {
+	return &WebSocketHandler{Hub: hub, PingInterval: 30 * time.Second}
+}
+
+func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrader has already replied with error
+		return
+	}
+
+	if h.PingInterval > 0 {
+		conn.SetReadDeadline(time.Now().Add(2 * h.PingInterval))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(2 * h.PingInterval))
+		})
+
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			ticker := time.NewTicker(h.PingInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					if conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.PingInterval)) != nil {
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	h.Hub.Serve(conn)
+}
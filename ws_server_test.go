@@ -0,0 +1,40 @@
+//go:build websocket
+
+package marketdata
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebSocketHandler(t *testing.T) {
+	hub := NewTickHub(nil)
+	server := httptest.NewServer(NewWebSocketHandler(hub))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	assert.Nil(t, conn.WriteJSON(HubRequest{Action: "subscribe", Id: "1", Symbols: []string{"SPY"},
+		Channels: []string{TradesChannel}}))
+	reply := HubMessage{}
+	assert.Nil(t, conn.ReadJSON(&reply))
+	assert.Equal(t, "subscribed", reply.Type)
+
+	hub.Publish(quoteAt(0, 10, 10.01))
+	hub.Publish(tradeAt(0, 10))
+
+	msg := HubMessage{}
+	assert.Nil(t, conn.ReadJSON(&msg))
+	assert.Equal(t, "trade", msg.Type)
+	assert.Equal(t, 10.0, msg.Tick.LastPrice)
+}